@@ -0,0 +1,73 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// Batch returns the url.Values encoding of each element of the slice or
+// array list, with the parameters of element i scoped within "name[i]", such
+// as "req[0][q]" and "req[1][q]".  This allows several requests to be sent in
+// one query string.  Unbatch reverses the encoding.
+func Batch(name string, list interface{}) (url.Values, error) {
+	return new(Config).Batch(name, list)
+}
+
+// Batch returns the encoding of list produced by c, as described in the
+// documentation for the package-level Batch function.  Each element is
+// scoped using c.Scope, within c.Within if set.
+func (c *Config) Batch(name string, list interface{}) (url.Values, error) {
+	lv := reflect.ValueOf(list)
+	for lv.Kind() == reflect.Ptr && !lv.IsNil() {
+		lv = lv.Elem()
+	}
+	if lv.Kind() != reflect.Slice && lv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("query: Batch() expects slice or array input. Got %v", lv.Kind())
+	}
+
+	scope := name
+	if c.Within != "" {
+		scope = c.scoped(c.Within, name)
+	}
+
+	values := make(url.Values)
+	for i := 0; i < lv.Len(); i++ {
+		elem := *c
+		elem.Within = c.scoped(scope, strconv.Itoa(i))
+		ev, err := elem.Values(lv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		for k, vs := range ev {
+			values[k] = append(values[k], vs...)
+		}
+	}
+	return values, nil
+}
+
+// Unbatch splits values encoded by Batch with the bracket scope name into the
+// parameters of each element, with the scopes removed from their names.  The
+// result has one element for each index up to the highest found, and
+// elements that had no parameters are empty.
+func Unbatch(values url.Values, name string) []url.Values {
+	scoped := Unscope(values, name)
+
+	var list []url.Values
+	for _, s := range Scopes(scoped) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 0 || strconv.Itoa(i) != s {
+			continue
+		}
+		for len(list) <= i {
+			list = append(list, make(url.Values))
+		}
+		list[i] = Unscope(scoped, s)
+	}
+	return list
+}
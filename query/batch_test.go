@@ -0,0 +1,80 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	type req struct {
+		Q    string `url:"q"`
+		Page int    `url:"page,omitempty"`
+		User struct {
+			Name string `url:"name"`
+		} `url:"user"`
+	}
+	list := []req{{Q: "a", Page: 2}, {Q: "b"}}
+	list[1].User.Name = "acme"
+
+	v, err := Batch("req", list)
+	if err != nil {
+		t.Errorf("Batch(%v) returned error: %v", list, err)
+	}
+	want := url.Values{
+		"req[0][q]":          {"a"},
+		"req[0][page]":       {"2"},
+		"req[0][user][name]": {""},
+		"req[1][q]":          {"b"},
+		"req[1][user][name]": {"acme"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Batch(%v) returned %v, want %v", list, v, want)
+	}
+
+	got := Unbatch(v, "req")
+	if len(got) != len(list) {
+		t.Fatalf("Unbatch(%v) returned %d elements, want %d", v, len(got), len(list))
+	}
+	for i, r := range list {
+		if w, _ := Values(r); !reflect.DeepEqual(w, got[i]) {
+			t.Errorf("Unbatch(%v)[%d] = %v, want %v", v, i, got[i], w)
+		}
+	}
+
+	// scoping follows the Config
+	c := &Config{Scope: DotScope, Within: "t1"}
+	v, err = c.Batch("req", &[1]map[string]int{{"n": 1}})
+	if err != nil {
+		t.Errorf("Batch returned error: %v", err)
+	}
+	if want := (url.Values{"t1.req.0.n": {"1"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Batch returned %v, want %v", v, want)
+	}
+
+	if _, err := Batch("req", list[0]); err == nil {
+		t.Errorf("Batch with struct input returned nil error, want error")
+	}
+}
+
+func TestUnbatch(t *testing.T) {
+	values := url.Values{
+		"req[2][q]":  {"c"},
+		"req[0][q]":  {"a"},
+		"req[x][q]":  {"x"},
+		"req[01][q]": {"y"},
+		"other":      {"z"},
+	}
+	got := Unbatch(values, "req")
+	want := []url.Values{{"q": {"a"}}, {}, {"q": {"c"}}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Unbatch(%v) returned %v, want %v", values, got, want)
+	}
+	if got := Unbatch(values, "none"); got != nil {
+		t.Errorf("Unbatch with no matching parameters returned %v, want nil", got)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CacheKey returns a key identifying the response to r for an HTTP cache,
+// when r is handled by decoding its query parameters into the struct type of
+// prototype.  The key is the path of r's URL followed by only those query
+// parameters listed by Keys(prototype), so that requests differing only in
+// parameters the handler ignores, such as "utm_source" tracking parameters,
+// share a key.
+//
+// Parameters are sorted by name and percent-encoded as for SigV4Query, but
+// the values of each parameter keep their order, as it may be significant.
+// The key does not include the method, host or any headers of r; callers
+// whose responses vary by these must add them.
+func CacheKey(r *http.Request, prototype interface{}) string {
+	values := Filter(r.URL.Query(), prototype)
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(r.URL.EscapedPath())
+	sep := byte('?')
+	for _, k := range names {
+		for _, v := range values[k] {
+			b.WriteByte(sep)
+			b.WriteString(escapeRFC3986(k))
+			b.WriteByte('=')
+			b.WriteString(escapeRFC3986(v))
+			sep = '&'
+		}
+	}
+	return b.String()
+}
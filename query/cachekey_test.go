@@ -0,0 +1,68 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCacheKey(t *testing.T) {
+	type params struct {
+		Q    string `url:"q"`
+		IDs  []int  `url:"id"`
+		Sort string `url:"sort,omitempty"`
+		User struct {
+			Name string `url:"name"`
+		} `url:"user"`
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/search", "/search"},
+		{"/search?utm_source=mail&fbclid=x", "/search"},
+		{"/search?q=a+b&utm_source=mail", "/search?q=a%20b"},
+		{"/search?sort=new&q=go&id=2&id=1", "/search?id=2&id=1&q=go&sort=new"},
+		{"/search?user%5Bname%5D=%C3%A9&user%5Bage%5D=3", "/search?user%5Bname%5D=%C3%A9"},
+		{"/a%2Fb?q=", "/a%2Fb?q="},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if got := CacheKey(r, params{}); got != tt.want {
+			t.Errorf("CacheKey(%q) returned %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestCacheKey_hash(t *testing.T) {
+	type filter struct {
+		Status string `url:"status"`
+	}
+	type params struct {
+		Q      string `url:"q"`
+		Filter filter `url:"filter,hash"`
+	}
+
+	c := &Config{HashStore: func(string, url.Values) error { return nil }}
+	var keys []string
+	for _, status := range []string{"open", "closed"} {
+		p := params{Q: "x", Filter: filter{status}}
+		v, err := c.Values(p)
+		if err != nil {
+			t.Fatalf("Values(%v) returned error: %v", p, err)
+		}
+		if got := Filter(v, p); got.Get("filter") == "" {
+			t.Errorf("Filter(%v) returned %v, want hashed filter parameter", v, got)
+		}
+		r := httptest.NewRequest("GET", "/s?"+v.Encode(), nil)
+		keys = append(keys, CacheKey(r, params{}))
+	}
+	if keys[0] == keys[1] {
+		t.Errorf("CacheKey returned %q for different hashed filters", keys[0])
+	}
+}
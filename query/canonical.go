@@ -0,0 +1,99 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SigV4Query returns the canonical query string of values as required by AWS
+// Signature Version 4.  Names and values are percent-encoded as described in
+// RFC 3986, leaving only unreserved characters unescaped and encoding spaces
+// as "%20", and the parameters are sorted by encoded name and then by encoded
+// value.  Parameters with an empty value are included as "name=".
+func SigV4Query(values url.Values) string {
+	return canonicalString(values)
+}
+
+// OAuthParams returns the normalized request parameters of RFC 5849, section
+// 3.4.1.3.2, for the url.Values encoding of v together with extra, which
+// typically holds the "oauth_" protocol parameters and any parameters from
+// the request body.  Any "oauth_signature" parameter is excluded.  Names and
+// values are percent-encoded and sorted as for SigV4Query.
+func OAuthParams(v interface{}, extra url.Values) (string, error) {
+	values, err := Values(v)
+	if err != nil {
+		return "", err
+	}
+	for k, vs := range extra {
+		values[k] = append(values[k], vs...)
+	}
+	delete(values, "oauth_signature")
+	return canonicalString(values), nil
+}
+
+// canonicalString returns values as percent-encoded "name=value" pairs,
+// sorted by name and then by value, and joined by '&'.
+func canonicalString(values url.Values) string {
+	var pairs []string
+	for k, vs := range values {
+		k = escapeRFC3986(k)
+		for _, v := range vs {
+			pairs = append(pairs, k+"="+escapeRFC3986(v))
+		}
+	}
+	sort.Sort(byNameValue(pairs))
+
+	var buf bytes.Buffer
+	for i, p := range pairs {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(p)
+	}
+	return buf.String()
+}
+
+// byNameValue sorts encoded "name=value" pairs by name and then by value.
+// Encoded names never contain '=', so the name is everything before it.
+type byNameValue []string
+
+func (s byNameValue) Len() int      { return len(s) }
+func (s byNameValue) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byNameValue) Less(i, j int) bool {
+	ni, vi := splitPair(s[i])
+	nj, vj := splitPair(s[j])
+	if ni != nj {
+		return ni < nj
+	}
+	return vi < vj
+}
+
+func splitPair(p string) (name, value string) {
+	i := strings.IndexByte(p, '=')
+	return p[:i], p[i+1:]
+}
+
+// escapeRFC3986 percent-encodes every byte of s other than the unreserved
+// characters of RFC 3986, using upper case hexadecimal digits.
+func escapeRFC3986(s string) string {
+	const hex = "0123456789ABCDEF"
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			buf.WriteByte(c)
+			continue
+		}
+		buf.WriteByte('%')
+		buf.WriteByte(hex[c>>4])
+		buf.WriteByte(hex[c&15])
+	}
+	return buf.String()
+}
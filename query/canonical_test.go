@@ -0,0 +1,73 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSigV4Query(t *testing.T) {
+	tests := []struct {
+		in   url.Values
+		want string
+	}{
+		{
+			url.Values{"Version": {"2010-05-08"}, "Action": {"ListUsers"}},
+			"Action=ListUsers&Version=2010-05-08",
+		},
+		{
+			url.Values{"a": {"z", "b", ""}, "a b": {"x/y+z~"}},
+			"a=&a=b&a=z&a%20b=x%2Fy%2Bz~",
+		},
+		{
+			// sorted by encoded name before value
+			url.Values{"a": {"2"}, "a-b": {"1"}},
+			"a=2&a-b=1",
+		},
+		{
+			url.Values{"ü": {"é"}},
+			"%C3%BC=%C3%A9",
+		},
+		{url.Values{}, ""},
+	}
+
+	for i, tt := range tests {
+		if got := SigV4Query(tt.in); got != tt.want {
+			t.Errorf("%d. SigV4Query(%v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestOAuthParams(t *testing.T) {
+	// example from RFC 5849, section 3.4.1.3
+	s := struct {
+		B5 string   `url:"b5"`
+		A3 []string `url:"a3"`
+		C2 string   `url:"c2"`
+	}{"=%3D", []string{"a", "2 q"}, ""}
+	extra := url.Values{
+		"c@":                     {""},
+		"a2":                     {"r b"},
+		"oauth_consumer_key":     {"9djdj82h48djs9d2"},
+		"oauth_token":            {"kkk9d7dh3k39sjv7"},
+		"oauth_signature_method": {"HMAC-SHA1"},
+		"oauth_timestamp":        {"137131201"},
+		"oauth_nonce":            {"7d8f3e4a"},
+		"oauth_signature":        {"ignored"},
+	}
+
+	got, err := OAuthParams(s, extra)
+	if err != nil {
+		t.Errorf("OAuthParams(%v) returned error: %v", s, err)
+	}
+	want := "a2=r%20b&a3=2%20q&a3=a&b5=%3D%253D&c%40=&c2=&" +
+		"oauth_consumer_key=9djdj82h48djs9d2&oauth_nonce=7d8f3e4a&" +
+		"oauth_signature_method=HMAC-SHA1&oauth_timestamp=137131201&" +
+		"oauth_token=kkk9d7dh3k39sjv7"
+	if got != want {
+		t.Errorf("OAuthParams(%v) returned %q, want %q", s, got, want)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CheckTags reports mistakes in the url tags of the struct type of v, and of
+// the struct types nested within it, that would otherwise cause parameters to
+// be silently missing or misencoded, such as a url tag on an unexported
+// field, which Values always skips.  The mistakes detected are those reported
+// by ParseFieldTag.  v may be a struct or a pointer to a struct, and need not
+// be populated.  CheckTags is intended to be called from tests.
+func CheckTags(v interface{}) error {
+	t := structType(v)
+	if t == nil {
+		return fmt.Errorf("query: CheckTags() expects struct input. Got %T", v)
+	}
+
+	var problems []string
+	checkTags(t, &problems, map[reflect.Type]bool{})
+	if len(problems) > 0 {
+		return errors.New("query: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkTags appends to problems a description of each mistake found in the
+// struct type t and the struct types reachable from its fields.  Types
+// already checked are recorded in seen.
+func checkTags(t reflect.Type, problems *[]string, seen map[reflect.Type]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for _, f := range cachedFields(t) {
+		if _, err := ParseFieldTag(f.sf); err != nil {
+			msg := strings.TrimPrefix(err.Error(), "query: field "+f.sf.Name+" ")
+			*problems = append(*problems, fmt.Sprintf("%v %s", f, msg))
+		}
+		if f.note != "" {
+			continue
+		}
+
+		ft := f.sf.Type
+		for {
+			switch ft.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+				ft = ft.Elem()
+				continue
+			}
+			break
+		}
+		if ft.Kind() == reflect.Struct && ft != timeType {
+			checkTags(ft, problems, seen)
+		}
+	}
+}
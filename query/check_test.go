@@ -0,0 +1,52 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"strings"
+	"testing"
+)
+
+type badInner struct {
+	value string `url:"value"`
+}
+
+func TestCheckTags(t *testing.T) {
+	good := struct {
+		A string `url:"a"`
+		b string
+		c string `url:"-"`
+		N Nested `url:"n"`
+	}{}
+	if err := CheckTags(&good); err != nil {
+		t.Errorf("CheckTags(%v) returned error: %v", good, err)
+	}
+
+	bad := struct {
+		A     string `url:"a"`
+		count int    `url:"count,omitempty"`
+		Inner []badInner
+		D     []int   `url:"d,comma,space"`
+		E     float64 `url:"e,prec=-1"`
+	}{}
+	err := CheckTags(bad)
+	if err == nil {
+		t.Fatalf("CheckTags(%v) returned nil error", bad)
+	}
+	for _, want := range []string{
+		`.count has url tag "count,omitempty"`,
+		`query.badInner.value has url tag "value"`,
+		`.D has conflicting delimiter options`,
+		`.E has invalid prec option`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckTags(%v) returned %q, want it to contain %q", bad, err, want)
+		}
+	}
+
+	if err := CheckTags(""); err == nil {
+		t.Errorf("expected CheckTags() to return an error on invalid input")
+	}
+}
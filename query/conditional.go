@@ -0,0 +1,79 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseHTTPTime parses a time encoded with the "httpdate" or "unix" option,
+// such as the value of an "if_modified_since" parameter.  It accepts the
+// three HTTP date formats understood by http.ParseTime, and a decimal number
+// of seconds since the Unix epoch.
+func ParseHTTPTime(s string) (time.Time, error) {
+	if t, err := http.ParseTime(s); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("query: invalid HTTP time %q", s)
+}
+
+// ModifiedSince reports whether modtime is after since, at the one second
+// precision of HTTP dates, as for an "if_modified_since" parameter.  A zero
+// since means no condition, and so always reports true.
+func ModifiedSince(modtime, since time.Time) bool {
+	if since.IsZero() {
+		return true
+	}
+	return modtime.Truncate(time.Second).After(since)
+}
+
+// An ETag is an entity tag, as in the HTTP ETag header: an opaque quoted
+// string such as `"v42"`, optionally prefixed by "W/" to mark it as weak.
+type ETag string
+
+// ParseETag parses an entity tag.  As query parameters are often written by
+// hand, an unquoted tag such as "v42" is accepted and quoted.
+func ParseETag(s string) (ETag, error) {
+	weak := strings.HasPrefix(s, "W/")
+	opaque := strings.TrimPrefix(s, "W/")
+	if len(opaque) >= 2 && opaque[0] == '"' && opaque[len(opaque)-1] == '"' {
+		opaque = opaque[1 : len(opaque)-1]
+	} else if weak {
+		return "", fmt.Errorf("query: invalid entity tag %q", s)
+	}
+	if opaque == "" || strings.ContainsAny(opaque, "\"\x7f") || strings.IndexFunc(opaque, func(r rune) bool { return r <= ' ' }) >= 0 {
+		return "", fmt.Errorf("query: invalid entity tag %q", s)
+	}
+
+	e := `"` + opaque + `"`
+	if weak {
+		e = "W/" + e
+	}
+	return ETag(e), nil
+}
+
+// Weak reports whether e is a weak entity tag.
+func (e ETag) Weak() bool {
+	return strings.HasPrefix(string(e), "W/")
+}
+
+// Match reports whether e and other match, using the weak comparison of
+// RFC 7232 if weak is set, under which only the opaque strings must be equal,
+// or the strong comparison otherwise, under which neither may be weak.
+// Use weak comparison for "if_none_match" conditions and strong comparison
+// for "if_match".
+func (e ETag) Match(other ETag, weak bool) bool {
+	if !weak && (e.Weak() || other.Weak()) {
+		return false
+	}
+	return e != "" && strings.TrimPrefix(string(e), "W/") == strings.TrimPrefix(string(other), "W/")
+}
@@ -0,0 +1,126 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValues_httpdate(t *testing.T) {
+	s := struct {
+		Since time.Time `url:"if_modified_since,httpdate"`
+		ETag  ETag      `url:"if_none_match"`
+	}{time.Date(2000, 1, 1, 12, 34, 56, 0, time.FixedZone("X", 3600)), `W/"v1"`}
+	want := url.Values{
+		"if_modified_since": {"Sat, 01 Jan 2000 11:34:56 GMT"},
+		"if_none_match":     {`W/"v1"`},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+func TestParseHTTPTime(t *testing.T) {
+	want := time.Date(2000, 1, 1, 11, 34, 56, 0, time.UTC)
+	for _, s := range []string{
+		"Sat, 01 Jan 2000 11:34:56 GMT",
+		"Saturday, 01-Jan-00 11:34:56 GMT",
+		"Sat Jan  1 11:34:56 2000",
+		"946726496",
+	} {
+		got, err := ParseHTTPTime(s)
+		if err != nil {
+			t.Errorf("ParseHTTPTime(%q) returned error: %v", s, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseHTTPTime(%q) returned %v, want %v", s, got, want)
+		}
+	}
+
+	for _, s := range []string{"", "yesterday", "2000-01-01T00:00:00Z"} {
+		if _, err := ParseHTTPTime(s); err == nil {
+			t.Errorf("ParseHTTPTime(%q) returned nil error, want error", s)
+		}
+	}
+}
+
+func TestModifiedSince(t *testing.T) {
+	since := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		modtime time.Time
+		since   time.Time
+		want    bool
+	}{
+		{since.Add(time.Second), since, true},
+		{since.Add(500 * time.Millisecond), since, false},
+		{since, since, false},
+		{since.Add(-time.Hour), since, false},
+		{since, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		if got := ModifiedSince(tt.modtime, tt.since); got != tt.want {
+			t.Errorf("ModifiedSince(%v, %v) returned %v, want %v", tt.modtime, tt.since, got, tt.want)
+		}
+	}
+}
+
+func TestParseETag(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ETag
+	}{
+		{`"v1"`, `"v1"`},
+		{`W/"v1"`, `W/"v1"`},
+		{`v1`, `"v1"`},
+	}
+	for _, tt := range tests {
+		got, err := ParseETag(tt.in)
+		if err != nil {
+			t.Errorf("ParseETag(%q) returned error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseETag(%q) returned %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", `""`, `W/v1`, `"a"b"`, `a b`, "\"a\x01\""} {
+		if got, err := ParseETag(s); err == nil {
+			t.Errorf("ParseETag(%q) returned %q, want error", s, got)
+		}
+	}
+}
+
+func TestETag_Match(t *testing.T) {
+	tests := []struct {
+		a, b ETag
+		weak bool
+		want bool
+	}{
+		{`"1"`, `"1"`, false, true},
+		{`"1"`, `"1"`, true, true},
+		{`W/"1"`, `"1"`, false, false},
+		{`W/"1"`, `"1"`, true, true},
+		{`W/"1"`, `W/"1"`, false, false},
+		{`W/"1"`, `W/"2"`, true, false},
+		{`"1"`, `"2"`, false, false},
+		{"", "", true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Match(tt.b, tt.weak); got != tt.want {
+			t.Errorf("%q.Match(%q, %v) returned %v, want %v", tt.a, tt.b, tt.weak, got, tt.want)
+		}
+	}
+	if !ETag(`W/"1"`).Weak() || ETag(`"1"`).Weak() {
+		t.Errorf("Weak returned wrong results")
+	}
+}
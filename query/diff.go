@@ -0,0 +1,126 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A Change describes how a URL parameter differs between two versions of a
+// parameter struct, as reported by Diff.
+type Change struct {
+	Param string // URL parameter name
+
+	// Breaking reports whether the change can break a client or server that
+	// uses the old version, such as a removed parameter.
+	Breaking bool
+
+	Desc string // description of the change, such as "removed"
+}
+
+func (c Change) String() string {
+	if c.Breaking {
+		return c.Param + ": " + c.Desc + " (breaking)"
+	}
+	return c.Param + ": " + c.Desc
+}
+
+// Diff compares the URL parameters of the struct types of old and new, which
+// need not be populated, and returns their differences: parameters that were
+// removed or added, or whose type or encoding options changed.  Changes to
+// parameters of old are listed first, in the order of Keys(old), followed by
+// additions in the order of Keys(new).  Diff returns nil if there are no
+// differences, or if either is not a struct.
+//
+// Removed parameters and changes of type or of options affecting the encoded
+// form are breaking.  Added parameters and changes to the "omitempty" option
+// are not.
+func Diff(old, new interface{}) []Change {
+	oldParams, oldKeys := diffParams(old)
+	newParams, newKeys := diffParams(new)
+	if oldParams == nil || newParams == nil {
+		return nil
+	}
+
+	var changes []Change
+	for _, k := range oldKeys {
+		o := oldParams[k]
+		n, ok := newParams[k]
+		if !ok {
+			changes = append(changes, Change{k, true, "removed"})
+			continue
+		}
+		if o.typ != n.typ && !(placeholder(o) && placeholder(n)) {
+			changes = append(changes, Change{k, true, fmt.Sprintf("type changed from %v to %v", o.typ, n.typ)})
+		}
+		if oo, no := wireOptions(o.opts), wireOptions(n.opts); oo != no {
+			changes = append(changes, Change{k, true, fmt.Sprintf("options changed from %q to %q", oo, no)})
+		}
+		if oe, ne := o.opts.Contains("omitempty"), n.opts.Contains("omitempty"); oe != ne {
+			desc := "omitempty removed"
+			if ne {
+				desc = "omitempty added"
+			}
+			changes = append(changes, Change{k, false, desc})
+		}
+	}
+	for _, k := range newKeys {
+		if _, ok := oldParams[k]; !ok {
+			changes = append(changes, Change{k, false, fmt.Sprintf("added with type %v", newParams[k].typ)})
+		}
+	}
+	return changes
+}
+
+// A diffParam describes the field producing a URL parameter.
+type diffParam struct {
+	opts tagOptions
+	typ  reflect.Type
+}
+
+// placeholder reports whether p is the parameter reported for a pointer to a
+// nested struct, which is encoded as an empty value when nil.  Its type is not
+// compared, as the parameters of the struct's fields describe its shape.
+func placeholder(p diffParam) bool {
+	t := p.typ
+	return t.Kind() == reflect.Struct && t != timeType && p.opts.delimiter() == 0 &&
+		!t.Implements(optionEncoderType) && !t.Implements(encoderType) && !reflect.PtrTo(t).Implements(encoderType)
+}
+
+// diffParams returns the fields producing each URL parameter of the struct
+// type of v, and the parameter names in order, or nil if v is not a struct.
+// Where several fields produce a parameter, the first is used.
+func diffParams(v interface{}) (map[string]diffParam, []string) {
+	t := structType(v)
+	if t == nil {
+		return nil, nil
+	}
+
+	params := make(map[string]diffParam)
+	var keys []string
+	new(Config).typeKeys(t, "", func(key string, opts tagOptions, ft reflect.Type) {
+		if _, ok := params[key]; !ok {
+			params[key] = diffParam{opts, ft}
+			keys = append(keys, key)
+		}
+	}, map[reflect.Type]bool{})
+	return params, keys
+}
+
+// wireOptions returns the options in opts that affect the encoded form of a
+// value, in order and joined by commas.
+func wireOptions(opts tagOptions) string {
+	var wire []string
+	for _, o := range opts {
+		switch o {
+		case "omitempty", "secret", "fingerprint":
+			continue
+		}
+		wire = append(wire, o)
+	}
+	return strings.Join(wire, ",")
+}
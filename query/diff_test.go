@@ -0,0 +1,88 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type v1 struct {
+		Query string `url:"q"`
+		Page  int    `url:"page"`
+		IDs   []int  `url:"id"`
+		Sort  string `url:"sort"`
+		Debug bool   `url:"debug"`
+	}
+	type v2 struct {
+		Query string   `url:"q,omitempty"`
+		Page  string   `url:"page"`
+		IDs   []int    `url:"id,comma"`
+		Debug bool     `url:"debug,secret"`
+		Tags  []string `url:"tag"`
+	}
+
+	got := Diff(v1{}, &v2{})
+	want := []Change{
+		{"q", false, "omitempty added"},
+		{"page", true, "type changed from int to string"},
+		{"id", true, `options changed from "" to "comma"`},
+		{"sort", true, "removed"},
+		{"tag", false, "added with type []string"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Diff returned %v, want %v", got, want)
+	}
+
+	if got := Diff(v1{}, v1{}); got != nil {
+		t.Errorf("Diff of identical types returned %v, want nil", got)
+	}
+	if got := Diff(v1{}, 1); got != nil {
+		t.Errorf("Diff with int returned %v, want nil", got)
+	}
+
+	type addrV1 struct {
+		City string `url:"city"`
+	}
+	type addrV2 struct {
+		City string `url:"city"`
+	}
+	type addrV3 struct {
+		City int `url:"city"`
+	}
+	type p1 struct {
+		A *addrV1 `url:"a"`
+	}
+	type p2 struct {
+		A *addrV2 `url:"a"`
+	}
+	type p3 struct {
+		A *addrV3 `url:"a"`
+	}
+	if got := Diff(p1{}, p2{}); got != nil {
+		t.Errorf("Diff of identically shaped struct pointers returned %v, want nil", got)
+	}
+	got = Diff(p1{}, p3{})
+	want = []Change{{"a[city]", true, "type changed from string to int"}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Diff returned %v, want %v", got, want)
+	}
+}
+
+func TestChange_String(t *testing.T) {
+	tests := []struct {
+		c    Change
+		want string
+	}{
+		{Change{"q", true, "removed"}, "q: removed (breaking)"},
+		{Change{"q", false, "omitempty added"}, "q: omitempty added"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("String() returned %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -22,11 +22,17 @@ package query
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -34,16 +40,43 @@ var timeType = reflect.TypeOf(time.Time{})
 
 var encoderType = reflect.TypeOf(new(Encoder)).Elem()
 
+var optionEncoderType = reflect.TypeOf(new(optionEncoder)).Elem()
+
+var stringerType = reflect.TypeOf(new(fmt.Stringer)).Elem()
+
+var extraValuerType = reflect.TypeOf(new(ExtraValuer)).Elem()
+
 // Encoder is an interface implemented by any type that wishes to encode
 // itself into URL values in a non-standard way.
 type Encoder interface {
 	EncodeValues(key string, v *url.Values) error
 }
 
+// ExtraValuer is an interface implemented by struct types that contribute
+// parameters computed from their fields, such as checksums or derived flags,
+// in addition to the fields themselves.  The values returned by ExtraValues
+// are added after those of the fields, and are scoped like them when the
+// struct is nested.  Keys does not list these parameters.
+type ExtraValuer interface {
+	ExtraValues() url.Values
+}
+
+// optionEncoder is implemented by types in this package whose encoding,
+// unlike that of an Encoder, depends on the options in the field's tag.
+type optionEncoder interface {
+	// encodeValues adds the encoding of the value to values under name.
+	encodeValues(c *Config, values url.Values, name string, opts tagOptions) error
+
+	// keys returns the URL parameter names encodeValues may add.
+	keys(name string, opts tagOptions) []string
+}
+
 // Values returns the url.Values encoding of v.
 //
 // Values expects to be passed a struct, and traverses it recursively using the
-// following encoding rules.
+// following encoding rules.  Values may also be passed a map with string keys,
+// such as a map[string]interface{} decoded from JSON, which is encoded as
+// described below for map fields but without an enclosing name.
 //
 // Each exported struct field is encoded as a URL parameter unless
 //
@@ -51,8 +84,8 @@ type Encoder interface {
 //	- the field is empty and its tag specifies the "omitempty" option
 //
 // The empty values are false, 0, any nil pointer or interface value, any array
-// slice, map, or string of length zero, and any time.Time that returns true
-// for IsZero().
+// slice, map, or string of length zero, any time.Time that returns true for
+// IsZero(), and the zero value of struct types in this package such as Money.
 //
 // The URL parameter name defaults to the struct field name but can be
 // specified in the struct field's tag value.  The "url" key in the struct
@@ -76,38 +109,225 @@ type Encoder interface {
 // For encoding individual field values, the following type-dependent rules
 // apply:
 //
+// Values whose type implements Encoder are encoded by calling their
+// EncodeValues method.  This includes fields whose pointer type implements
+// Encoder, provided the field is addressable, as it is when Values is passed a
+// pointer to the struct.
+//
+// Including the "ro" option marks a field as read-only, such as a parameter
+// that a server echoes back, so it is never encoded.  The "wo" option marks
+// a field as write-only, such as a client-generated nonce, and has no effect
+// on encoding; decoders in other packages may honor either.
+//
+// Structs that implement ExtraValuer, including v itself, also encode the
+// values returned by their ExtraValues method.
+//
 // Boolean values default to encoding as the strings "true" or "false".
 // Including the "int" option signals that the field should be encoded as the
 // strings "1" or "0".
 //
 // time.Time values default to encoding as RFC3339 timestamps.  Including the
 // "unix" option signals that the field should be encoded as a Unix time (see
-// time.Unix())
+// time.Unix()).  The "unixmilli" and "unixnano" options similarly encode the
+// number of milliseconds or nanoseconds since the Unix epoch, and the
+// "httpdate" option encodes an HTTP date in UTC (see http.TimeFormat), such
+// as "Mon, 02 Jan 2006 15:04:05 GMT".
+//
+// Float values default to encoding in the shortest form that round-trips,
+// switching to exponent notation for very large or small values, unless
+// Config.FloatFormat says otherwise.  Including the "prec=n" option encodes
+// the field with exactly n digits after the decimal point, and the "noexp"
+// option encodes it in the shortest form without an exponent.  e.g:
+//
+// 	// Field appears as "lat=51.50"
+// 	Field float64 `url:"lat,prec=2"`
 //
 // Slice and Array values default to encoding as multiple URL values of the
 // same name.  Including the "comma" option signals that the field should be
 // encoded as a single comma-delimited value.  Including the "space" option
-// similarly encodes the value as a single space-delimited string. Including
-// the "brackets" option signals that the multiple URL values should have "[]"
-// appended to the value name.
+// similarly encodes the value as a single space-delimited string, and the
+// "pipe" option as a single "|"-delimited string. Including the "brackets"
+// option signals that the multiple URL values should have "[]" appended to
+// the value name.
+//
+// Map values with string keys, such as url.Values, are encoded as one URL
+// parameter per map key, named by appending the key in brackets to the
+// field's name.  Slice and Array map elements are encoded as multiple URL
+// values of that name, and map and struct elements, including those held in
+// interface values, are encoded as nested scopes.  e.g:
+//
+// 	"extra[a]=1&extra[a]=2&extra[b][c]=3"
+//
+// Including the "comma", "space" or "pipe" option on a map or nested struct
+// field instead encodes it as a single value of alternating names and
+// values, such as "color=R,100,G,200", with map keys in sorted order.
+//
+// These rules correspond to the OpenAPI 3 query parameter styles: the
+// default encoding of slices is style "form", and of maps and nested structs
+// with BracketScope, style "deepObject".  The "comma", "space" and "pipe"
+// options give styles "form", "spaceDelimited" and "pipeDelimited" without
+// explode, and anonymous struct fields give style "form" with explode.
 //
 // Anonymous struct fields are usually encoded as if their inner exported
 // fields were fields in the outer struct, subject to the standard Go
-// visibility rules.  An anonymous struct field with a name given in its URL
-// tag is treated as having that name, rather than being anonymous.
+// visibility rules.  This includes anonymous pointers to structs, unless the
+// pointer is nil.  An anonymous struct field with a name given in its URL tag
+// is treated as having that name, rather than being anonymous.
+//
+// Non-nil pointer values are encoded as the value pointed to.  Nil pointers
+// to slices, arrays and maps are omitted; other nil pointers are encoded as
+// empty values.
 //
-// Non-nil pointer values are encoded as the value pointed to.
+// Including the "secret" option marks a field as sensitive, such as an access
+// token.  It does not change the output of Values, but each parameter of such
+// fields is replaced by a placeholder when encoding with Redact.
+//
+// Including the "audiences=a|b" option encodes a field only for a Config
+// whose Audience is listed, so that one struct can produce both an internal
+// URL and a trimmed public one.  Values omits such fields.
 //
 // Nested structs are encoded including parent fields in value names for
 // scoping. e.g:
 //
 // 	"user[name]=acme&user[addr][postcode]=1234&user[addr][city]=SFO"
 //
-// All other values are encoded using their default string representation.
+// A Config with a Scope function can use other scoping conventions, such as
+// "user.addr.city".  Including the "flatten" option on a nested struct field
+// instead joins the names of all parameters within it by underscores, such as
+// "user_addr_city", regardless of the Config.
+//
+// Including the "group" option on a slice or array of structs encodes it as
+// parallel arrays: the fields of the elements are encoded as if they were
+// fields of the outer struct, with the values of the first element first.
+// Each element must encode to a single value for each of its parameters,
+// with a missing parameter taken as empty so that the arrays stay the same
+// length.  e.g:
+//
+// 	// Items appears as "name=a&name=b&qty=1&qty=2"
+// 	Items []LineItem `url:",group"`
+//
+// A field whose name lists several names joined by "+" is a composite field,
+// encoded as one parameter per name.  The values are the elements of a slice
+// or array, or the fields of a struct, in order, and there must be exactly
+// one for each name.  e.g:
+//
+// 	// Field appears as "lat=51.5&lng=-0.1"
+// 	Field [2]float64 `url:"lat+lng"`
+//
+// All other values are encoded using their default string representation, as
+// formatted by fmt.Sprint.  In particular, a type with a String method (see
+// fmt.Stringer) is encoded using that method.
 //
 // Multiple fields that encode to the same URL parameter name will be included
 // as multiple URL values of the same name.
+//
+// The values of each URL parameter are in a deterministic order: slice and
+// array elements in index order, map entries sorted by key, and values from
+// different fields in field order.  Combined with url.Values.Encode, which
+// sorts by parameter name, the same input always yields the same query
+// string.  Config.SortValues additionally sorts the values of each parameter.
 func Values(v interface{}) (url.Values, error) {
+	return new(Config).Values(v)
+}
+
+// ScopedValues returns the url.Values encoding of v with every parameter
+// scoped within scope, as if v were a nested struct field named scope, such
+// as "filter[status]" for scope "filter".  It composes a query string from
+// independent parts without a wrapper struct, and is reversed by Unscope.
+func ScopedValues(scope string, v interface{}) (url.Values, error) {
+	return (&Config{Within: scope}).Values(v)
+}
+
+// A Config controls the encoding performed by its Values method.  The zero
+// Config encodes exactly as the package-level Values function.
+type Config struct {
+	// Trace, if non-nil, receives one line for each struct field visited
+	// during encoding, describing the URL parameter name and options used
+	// and the resulting value, or why the field was skipped.  It is intended
+	// for debugging missing or unexpected parameters.
+	Trace io.Writer
+
+	// Redact causes each parameter of fields with the "secret" option to be
+	// encoded as the Redacted placeholder rather than their actual value.
+	Redact bool
+
+	// HashStore, if non-nil, enables the "hash" option.  Each field with that
+	// option is first encoded on its own, and the resulting url.Values is
+	// passed to HashStore along with its hex-encoded SHA-256 hash.  The field
+	// is then encoded as that hash in place of its values, keeping URLs short.
+	// HashStore is responsible for retaining the values so that they can be
+	// looked up by hash when the URL is received.
+	HashStore func(hash string, values url.Values) error
+
+	// Scope, if non-nil, returns the URL parameter name for a value named
+	// name within the nested struct or map named scope.  If nil, BracketScope
+	// is used.
+	Scope func(scope, name string) string
+
+	// FloatFormat, if non-zero, is the format used for float values, as for
+	// strconv.FormatFloat, with precision FloatPrecision.  For example, a
+	// FloatFormat of 'f' and FloatPrecision of 2 always writes two decimal
+	// places, and 'f' with -1 writes the fewest digits needed, but never an
+	// exponent.  Floats whose type has a String method are not affected.
+	FloatFormat    byte
+	FloatPrecision int
+
+	// SortValues causes the values of each URL parameter to be sorted, so
+	// that the result does not depend on slice or field order.  This is useful
+	// when the encoded form is compared or hashed rather than sent.
+	SortValues bool
+
+	// Rename, if non-nil, maps the path of a struct field to the URL
+	// parameter name to use in place of the name in its tag, or to "-" to
+	// omit the field.  A path is the Go names of the fields leading to the
+	// field from the encoded struct, joined by dots, such as "User.Name";
+	// fields promoted from embedded structs are named as if declared in the
+	// outer struct.  A renamed field is still scoped within its enclosing
+	// structs.  Fields of structs held in maps are not renamed.
+	Rename map[string]string
+
+	// Prefix, if not empty, is added to the start of every URL parameter
+	// name, such as "x_" for gateways that namespace forwarded parameters.
+	// Scoping applies first, so a nested field is named "x_user[name]".
+	// StripPrefix reverses this.
+	Prefix string
+
+	// Within, if not empty, scopes every URL parameter within a struct of
+	// that name, as if v were a nested struct field named Within.  For
+	// example, "t123" names the parameters "t123[q]" and "t123[user][name]",
+	// so that the parameters of several tenants can share one query.  Unscope
+	// selects the parameters within one such scope.
+	Within string
+
+	// Audience selects the fields with the "audiences" option to encode.
+	// Such a field is encoded only if Audience is one of the audiences it
+	// lists, separated by "|", such as "audiences=internal|ops".  Fields
+	// without the option are always encoded, so the zero Config produces
+	// the URL for a public audience.
+	Audience string
+
+	// path is the Rename path of the struct being encoded, if nested.
+	path string
+}
+
+// BracketScope returns name within scope as "scope[name]".
+func BracketScope(scope, name string) string {
+	return scope + "[" + name + "]"
+}
+
+// DotScope returns name within scope as "scope.name".
+func DotScope(scope, name string) string {
+	return scope + "." + name
+}
+
+// UnderscoreScope returns name within scope as "scope_name".
+func UnderscoreScope(scope, name string) string {
+	return scope + "_" + name
+}
+
+// Values returns the url.Values encoding of v, following the rules described
+// in the documentation for the package-level Values function.
+func (c *Config) Values(v interface{}) (url.Values, error) {
 	values := make(url.Values)
 	val := reflect.ValueOf(v)
 	for val.Kind() == reflect.Ptr {
@@ -121,120 +341,707 @@ func Values(v interface{}) (url.Values, error) {
 		return values, nil
 	}
 
-	if val.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("query: Values() expects struct input. Got %v", val.Kind())
+	var err error
+	switch {
+	case val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String:
+		err = c.reflectMap(values, val, c.Within, nil)
+	case val.Kind() == reflect.Struct:
+		err = c.reflectValue(values, val, c.Within)
+	default:
+		return nil, fmt.Errorf("query: Values() expects struct or map input. Got %v", val.Kind())
 	}
 
-	err := reflectValue(values, val, "")
+	if c.SortValues {
+		for _, vs := range values {
+			sort.Strings(vs)
+		}
+	}
+	if c.Prefix != "" {
+		prefixed := make(url.Values, len(values))
+		for k, vs := range values {
+			prefixed[c.Prefix+k] = vs
+		}
+		values = prefixed
+	}
 	return values, err
 }
 
+// scoped returns the name of name within the non-empty scope.
+func (c *Config) scoped(scope, name string) string {
+	if c.Scope == nil {
+		return BracketScope(scope, name)
+	}
+	return c.Scope(scope, name)
+}
+
+// tracef writes a formatted line to c.Trace, if set.
+func (c *Config) tracef(format string, a ...interface{}) {
+	if c.Trace == nil {
+		return
+	}
+	fmt.Fprintf(c.Trace, "query: "+format+"\n", a...)
+}
+
 // reflectValue populates the values parameter from the struct fields in val.
-// Embedded structs are followed recursively (using the rules defined in the
-// Values function documentation) breadth-first.
-func reflectValue(values url.Values, val reflect.Value, scope string) error {
-	var embedded []reflect.Value
-
-	typ := val.Type()
-	for i := 0; i < typ.NumField(); i++ {
-		sf := typ.Field(i)
-		if sf.PkgPath != "" { // unexported
+// Fields of embedded structs are included after the fields of val (using the
+// rules defined in the Values function documentation), as listed by
+// cachedFields.
+func (c *Config) reflectValue(values url.Values, val reflect.Value, scope string) error {
+	for _, f := range cachedFields(val.Type()) {
+		if f.note != "" {
+			c.tracef("%v: %s", f, f.note)
 			continue
 		}
 
-		sv := val.Field(i)
-		tag := sf.Tag.Get("url")
-		if tag == "-" {
+		sv, ok := fieldByIndex(val, f.index)
+		if !ok {
+			c.tracef("%v: skipped, embedded in nil pointer", f)
 			continue
 		}
-		name, opts := parseTag(tag)
-		if name == "" {
-			if sf.Anonymous && sv.Kind() == reflect.Struct {
-				// save embedded struct for later processing
-				embedded = append(embedded, sv)
-				continue
-			}
 
-			name = sf.Name
+		fname, omitted := c.fieldName(f)
+		if omitted {
+			c.tracef("%v: skipped, renamed to \"-\"", f)
+			continue
 		}
-
+		name, opts := fname, f.opts
 		if scope != "" {
-			name = scope + "[" + name + "]"
+			name = c.scoped(scope, name)
 		}
 
 		if opts.Contains("omitempty") && isEmptyValue(sv) {
+			c.tracef("%v: key %q %v: skipped, empty", f, name, opts)
+			continue
+		}
+
+		if opts.Contains("ro") {
+			c.tracef("%v: key %q %v: skipped, read-only", f, name, opts)
+			continue
+		}
+
+		if !c.forAudience(opts) {
+			c.tracef("%v: key %q %v: skipped, not for audience %q", f, name, opts, c.Audience)
+			continue
+		}
+
+		if opts.Contains("group") {
+			err := c.reflectMasked(values, f, func(c *Config, values url.Values) error {
+				return c.reflectGroup(values, f, sv, scope)
+			})
+			if err != nil {
+				return err
+			}
 			continue
 		}
 
-		if sv.Type().Implements(encoderType) {
-			m := sv.Interface().(Encoder)
-			if err := m.EncodeValues(name, &values); err != nil {
+		if names := compositeNames(fname); names != nil {
+			err := c.reflectMasked(values, f, func(c *Config, values url.Values) error {
+				return c.reflectComposite(values, f, sv, scope, names)
+			})
+			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		if sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array {
-			var del byte
-			if opts.Contains("comma") {
-				del = ','
-			} else if opts.Contains("space") {
-				del = ' '
-			} else if opts.Contains("brackets") {
-				name = name + "[]"
+		if err := c.reflectField(values, f, sv, name); err != nil {
+			return err
+		}
+	}
+
+	var ev ExtraValuer
+	if val.Type().Implements(extraValuerType) {
+		ev = val.Interface().(ExtraValuer)
+	} else if val.CanAddr() && reflect.PtrTo(val.Type()).Implements(extraValuerType) {
+		ev = val.Addr().Interface().(ExtraValuer)
+	}
+	if ev != nil {
+		for k, vs := range ev.ExtraValues() {
+			name := k
+			if scope != "" {
+				name = c.scoped(scope, k)
 			}
+			values[name] = append(values[name], vs...)
+			c.tracef("%v: key %q: %q from ExtraValues", val.Type(), name, vs)
+		}
+	}
 
-			if del != 0 {
-				s := new(bytes.Buffer)
-				first := true
-				for i := 0; i < sv.Len(); i++ {
-					if first {
-						first = false
-					} else {
-						s.WriteByte(del)
-					}
-					s.WriteString(valueString(sv.Index(i), opts))
-				}
-				values.Add(name, s.String())
-			} else {
-				for i := 0; i < sv.Len(); i++ {
-					values.Add(name, valueString(sv.Index(i), opts))
+	return nil
+}
+
+// reflectField adds the encoding of sv, the value of field f, to values using
+// the parameter name name.
+func (c *Config) reflectField(values url.Values, f field, sv reflect.Value, name string) error {
+	opts := f.opts
+	if c.Redact && opts.Contains("secret") {
+		return c.reflectMasked(values, f, func(c *Config, values url.Values) error {
+			inner := *c
+			inner.Redact = false
+			return inner.reflectField(values, f, sv, name)
+		})
+	}
+
+	if c.HashStore != nil && opts.Contains("hash") {
+		inner := *c
+		inner.HashStore = nil
+		full := make(url.Values)
+		if err := inner.reflectField(full, f, sv, name); err != nil {
+			return err
+		}
+		sum := sha256.Sum256([]byte(canonicalString(full)))
+		h := hex.EncodeToString(sum[:])
+		if err := c.HashStore(h, full); err != nil {
+			c.tracef("%v: key %q %v: HashStore error: %v", f, name, opts, err)
+			return err
+		}
+		values.Add(name, h)
+		c.tracef("%v: key %q %v: hashed as %q", f, name, opts, h)
+		return nil
+	}
+
+	if sv.Type().Implements(optionEncoderType) {
+		if sv.Kind() == reflect.Ptr {
+			if sv.IsNil() {
+				switch sv.Type().Elem().Kind() {
+				case reflect.Slice, reflect.Array, reflect.Map:
+					c.tracef("%v: key %q %v: skipped, nil", f, name, opts)
+				default:
+					values.Add(name, "")
+					c.tracef("%v: key %q %v: nil", f, name, opts)
 				}
+				return nil
 			}
-			continue
+			sv = sv.Elem()
 		}
+		if err := sv.Interface().(optionEncoder).encodeValues(c, values, name, opts); err != nil {
+			c.tracef("%v: key %q %v: %v", f, name, opts, err)
+			return err
+		}
+		c.tracef("%v: key %q %v: %v", f, name, opts, sv.Interface())
+		return nil
+	}
 
-		if sv.Type() == timeType {
-			values.Add(name, valueString(sv, opts))
-			continue
+	var m Encoder
+	if sv.Type().Implements(encoderType) {
+		m = sv.Interface().(Encoder)
+	} else if sv.CanAddr() && reflect.PtrTo(sv.Type()).Implements(encoderType) {
+		m = sv.Addr().Interface().(Encoder)
+	}
+	if m != nil {
+		if err := m.EncodeValues(name, &values); err != nil {
+			c.tracef("%v: key %q %v: EncodeValues error: %v", f, name, opts, err)
+			return err
 		}
+		c.tracef("%v: key %q %v: encoded by EncodeValues", f, name, opts)
+		return nil
+	}
 
-		for sv.Kind() == reflect.Ptr {
+	if sv.Kind() == reflect.Ptr {
+		switch sv.Type().Elem().Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
 			if sv.IsNil() {
-				break
+				c.tracef("%v: key %q %v: skipped, nil", f, name, opts)
+				return nil
 			}
 			sv = sv.Elem()
 		}
+	}
 
-		if sv.Kind() == reflect.Struct {
-			reflectValue(values, sv, name)
-			continue
+	if sv.Kind() == reflect.Map && sv.Type().Key().Kind() == reflect.String {
+		if del := opts.delimiter(); del != 0 {
+			s := c.joinObject(sv, opts, del)
+			values.Add(name, s)
+			c.tracef("%v: key %q %v: %q", f, name, opts, s)
+			return nil
+		}
+		c.tracef("%v: key %q %v: map with %d keys", f, name, opts, sv.Len())
+		return c.reflectMap(values, sv, name, opts)
+	}
+
+	if sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array {
+		del := opts.delimiter()
+		if del == 0 && opts.Contains("brackets") {
+			name = name + "[]"
 		}
 
-		values.Add(name, valueString(sv, opts))
+		if del != 0 {
+			s := new(bytes.Buffer)
+			first := true
+			for i := 0; i < sv.Len(); i++ {
+				if first {
+					first = false
+				} else {
+					s.WriteByte(del)
+				}
+				s.WriteString(c.valueString(sv.Index(i), opts))
+			}
+			values.Add(name, s.String())
+			c.tracef("%v: key %q %v: %q", f, name, opts, s.String())
+		} else {
+			n := len(values[name])
+			for i := 0; i < sv.Len(); i++ {
+				values.Add(name, c.valueString(sv.Index(i), opts))
+			}
+			c.tracef("%v: key %q %v: %q", f, name, opts, values[name][n:])
+		}
+		return nil
 	}
 
-	for _, f := range embedded {
-		if err := reflectValue(values, f, scope); err != nil {
+	if sv.Type() == timeType {
+		s := c.valueString(sv, opts)
+		values.Add(name, s)
+		c.tracef("%v: key %q %v: %q", f, name, opts, s)
+		return nil
+	}
+
+	for sv.Kind() == reflect.Ptr {
+		if sv.IsNil() {
+			break
+		}
+		sv = sv.Elem()
+	}
+
+	if sv.Kind() == reflect.Struct && opts.delimiter() != 0 {
+		s := c.joinObject(sv, opts, opts.delimiter())
+		values.Add(name, s)
+		c.tracef("%v: key %q %v: %q", f, name, opts, s)
+		return nil
+	}
+
+	if sv.Kind() == reflect.Struct {
+		inner := *c
+		inner.path = c.fieldPath(f)
+		if opts.Contains("flatten") {
+			c.tracef("%v: key %q %v: flattened nested struct", f, name, opts)
+			inner.Scope = UnderscoreScope
+			return inner.reflectValue(values, sv, name)
+		}
+		c.tracef("%v: key %q %v: nested struct", f, name, opts)
+		return inner.reflectValue(values, sv, name)
+	}
+
+	s := c.valueString(sv, opts)
+	values.Add(name, s)
+	c.tracef("%v: key %q %v: %q", f, name, opts, s)
+	return nil
+}
+
+// joinObject returns the map or struct ov as alternating names and values,
+// separated by del.  Map keys are sorted, and struct fields are named as in
+// their tags, omitting "omitempty" fields that are empty.
+func (c *Config) joinObject(ov reflect.Value, opts tagOptions, del byte) string {
+	var parts []string
+	if ov.Kind() == reflect.Map {
+		keys := ov.MapKeys()
+		sort.Sort(byString(keys))
+		for _, k := range keys {
+			parts = append(parts, k.String(), c.valueString(ov.MapIndex(k), opts))
+		}
+	} else {
+		for _, f := range cachedFields(ov.Type()) {
+			fv, ok := fieldByIndex(ov, f.index)
+			if f.note != "" || !ok || f.opts.Contains("omitempty") && isEmptyValue(fv) {
+				continue
+			}
+			parts = append(parts, f.name, c.valueString(fv, f.opts))
+		}
+	}
+	return strings.Join(parts, string(del))
+}
+
+// reflectGroup adds the elements of sv, the value of field f with the "group"
+// option, to values as parallel arrays of the parameters of its elements,
+// which are encoded within scope.
+func (c *Config) reflectGroup(values url.Values, f field, sv reflect.Value, scope string) error {
+	for sv.Kind() == reflect.Ptr {
+		if sv.IsNil() {
+			c.tracef("%v: %v: skipped, nil", f, f.opts)
+			return nil
+		}
+		sv = sv.Elem()
+	}
+	if et := sv.Type(); (et.Kind() != reflect.Slice && et.Kind() != reflect.Array) || derefType(et.Elem()).Kind() != reflect.Struct {
+		return fmt.Errorf("query: group field %v must be a slice or array of structs. Got %v", f, sv.Type())
+	}
+
+	inner := *c
+	inner.path = c.fieldPath(f)
+	elems := make([]url.Values, sv.Len())
+	var keys []string
+	seen := make(map[string]bool)
+	for i := range elems {
+		elems[i] = make(url.Values)
+		ev := sv.Index(i)
+		for ev.Kind() == reflect.Ptr && !ev.IsNil() {
+			ev = ev.Elem()
+		}
+		if ev.Kind() == reflect.Struct {
+			if err := inner.reflectValue(elems[i], ev, scope); err != nil {
+				return err
+			}
+		}
+		for k := range elems[i] {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for i, ev := range elems {
+			switch vs := ev[k]; len(vs) {
+			case 0:
+				values.Add(k, "")
+			case 1:
+				values.Add(k, vs[0])
+			default:
+				return fmt.Errorf("query: group field %v: element %d has %d values for %q", f, i, len(vs), k)
+			}
+		}
+	}
+	c.tracef("%v: %v: group of %d elements with keys %q", f, f.opts, len(elems), keys)
+	return nil
+}
+
+// reflectMasked adds the parameters of f, a field that may produce several,
+// to values by calling encode with c.  If c redacts or hashes f, its
+// parameters are instead encoded separately, and each is added to values as a
+// single Redacted value or hash, so that the names of the parameters are those
+// that Values produces.
+func (c *Config) reflectMasked(values url.Values, f field, encode func(c *Config, values url.Values) error) error {
+	redact := c.Redact && f.opts.Contains("secret")
+	if !redact && (c.HashStore == nil || !f.opts.Contains("hash")) {
+		return encode(c, values)
+	}
+
+	inner := *c
+	inner.HashStore = nil
+	full := make(url.Values)
+	if err := encode(&inner, full); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(full))
+	for k := range full {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	s := Redacted
+	if !redact {
+		sum := sha256.Sum256([]byte(canonicalString(full)))
+		s = hex.EncodeToString(sum[:])
+		if err := c.HashStore(s, full); err != nil {
+			c.tracef("%v: keys %q %v: HashStore error: %v", f, keys, f.opts, err)
 			return err
 		}
 	}
+	for _, k := range keys {
+		values.Add(k, s)
+	}
+	if redact {
+		c.tracef("%v: keys %q %v: redacted", f, keys, f.opts)
+	} else {
+		c.tracef("%v: keys %q %v: hashed as %q", f, keys, f.opts, s)
+	}
+	return nil
+}
 
+// compositeNames returns the names joined by "+" in name, or nil if name is
+// not composite.
+func compositeNames(name string) []string {
+	if !strings.Contains(name, "+") {
+		return nil
+	}
+	return strings.Split(name, "+")
+}
+
+// reflectComposite adds sv, the value of the composite field f, to values as
+// one parameter for each of names, within scope.  A nil pointer is encoded as
+// empty values.
+func (c *Config) reflectComposite(values url.Values, f field, sv reflect.Value, scope string, names []string) error {
+	var parts []reflect.Value
+	for sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	switch sv.Kind() {
+	case reflect.Ptr:
+		for range names {
+			parts = append(parts, sv)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < sv.Len(); i++ {
+			parts = append(parts, sv.Index(i))
+		}
+	case reflect.Struct:
+		for _, pf := range cachedFields(sv.Type()) {
+			if pv, ok := fieldByIndex(sv, pf.index); ok && pf.note == "" {
+				parts = append(parts, pv)
+			}
+		}
+	default:
+		return fmt.Errorf("query: composite field %v must be a slice, array or struct. Got %v", f, sv.Type())
+	}
+	if len(parts) != len(names) {
+		return fmt.Errorf("query: composite field %v has %d values for %d names %q", f, len(parts), len(names), names)
+	}
+
+	for i, name := range names {
+		if scope != "" {
+			name = c.scoped(scope, name)
+		}
+		s := c.valueString(parts[i], f.opts)
+		values.Add(name, s)
+		c.tracef("%v: key %q %v: %q", f, name, f.opts, s)
+	}
 	return nil
 }
 
+// structType returns the struct type of v, which may be a struct or a pointer
+// to one, or nil if v is neither.
+func structType(v interface{}) reflect.Type {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// derefType returns t with any pointer indirections removed.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// forAudience reports whether a field with options opts is encoded for
+// c.Audience.
+func (c *Config) forAudience(opts tagOptions) bool {
+	audiences, ok := opts.Value("audiences")
+	if !ok {
+		return true
+	}
+	for _, a := range strings.Split(audiences, "|") {
+		if a == c.Audience && a != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPath returns the Rename path of f, a field of the struct at c.path.
+func (c *Config) fieldPath(f field) string {
+	if c.path == "" {
+		return f.sf.Name
+	}
+	return c.path + "." + f.sf.Name
+}
+
+// fieldName returns the unscoped URL parameter name of f, a field of the
+// struct at c.path, after applying c.Rename, and whether c.Rename omits f by
+// renaming it to "-".
+func (c *Config) fieldName(f field) (name string, omitted bool) {
+	if name, ok := c.Rename[c.fieldPath(f)]; ok {
+		return name, name == "-"
+	}
+	return f.name, false
+}
+
+// reflectMap populates the values parameter from the elements of the map mv,
+// which must have string keys.  Each element is named by its key, in brackets
+// if scope is not empty.  Elements that are themselves maps or structs are
+// followed recursively, and slice and array elements are encoded as multiple
+// values, or by index if they are maps or structs, as described for
+// reflectMapValue.  Keys are visited in sorted order.
+func (c *Config) reflectMap(values url.Values, mv reflect.Value, scope string, opts tagOptions) error {
+	keys := mv.MapKeys()
+	sort.Sort(byString(keys))
+	for _, k := range keys {
+		name := k.String()
+		if scope != "" {
+			name = c.scoped(scope, name)
+		}
+
+		if err := c.reflectMapValue(values, mv.MapIndex(k), name, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reflectMapValue adds ev, a value of a map or an element of a slice in one,
+// to values as the parameter name, or within the scope name if it is a map or
+// struct.  Maps and structs in a slice are scoped by their index, such as
+// "items[0][sku]", so that values decoded from JSON can be forwarded.
+func (c *Config) reflectMapValue(values url.Values, ev reflect.Value, name string, opts tagOptions) error {
+	for ev.Kind() == reflect.Interface || ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			values.Add(name, "")
+			return nil
+		}
+		ev = ev.Elem()
+	}
+
+	switch {
+	case ev.Kind() == reflect.Map && ev.Type().Key().Kind() == reflect.String:
+		return c.reflectMap(values, ev, name, opts)
+	case ev.Kind() == reflect.Struct && ev.Type() != timeType:
+		elem := *c
+		elem.Rename = nil
+		return elem.reflectValue(values, ev, name)
+	case ev.Kind() == reflect.Slice || ev.Kind() == reflect.Array:
+		for i := 0; i < ev.Len(); i++ {
+			el := ev.Index(i)
+			for (el.Kind() == reflect.Interface || el.Kind() == reflect.Ptr) && !el.IsNil() {
+				el = el.Elem()
+			}
+			elName := name
+			if el.Kind() == reflect.Map && el.Type().Key().Kind() == reflect.String || el.Kind() == reflect.Struct && el.Type() != timeType {
+				elName = c.scoped(name, strconv.Itoa(i))
+			}
+			if err := c.reflectMapValue(values, el, elName, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if (ev.Kind() == reflect.Float32 || ev.Kind() == reflect.Float64) && c.FloatFormat == 0 {
+		// such as numbers decoded from JSON, which are all float64
+		opts = append(opts[:len(opts):len(opts)], "noexp")
+	}
+	values.Add(name, c.valueString(ev, opts))
+	return nil
+}
+
+// A field describes how a single struct field is encoded.  The fields of a
+// struct type are computed once and cached by cachedFields.
+type field struct {
+	sf    reflect.StructField // the field as declared in its struct
+	owner reflect.Type        // struct type declaring the field
+	index []int               // index sequence for fieldByIndex
+	name  string              // unscoped URL parameter name
+	opts  tagOptions
+
+	// note, if not empty, explains why the field is not encoded.
+	note string
+}
+
+func (f field) String() string {
+	return f.owner.String() + "." + f.sf.Name
+}
+
+var fieldCache struct {
+	sync.RWMutex
+	m map[reflect.Type][]field
+}
+
+// cachedFields is like typeFields but uses a cache to avoid repeated work.
+func cachedFields(t reflect.Type) []field {
+	fieldCache.RLock()
+	f, ok := fieldCache.m[t]
+	fieldCache.RUnlock()
+	if ok {
+		return f
+	}
+
+	f = typeFields(t, nil, map[reflect.Type]bool{})
+	fieldCache.Lock()
+	if fieldCache.m == nil {
+		fieldCache.m = make(map[reflect.Type][]field)
+	}
+	fieldCache.m[t] = f
+	fieldCache.Unlock()
+	return f
+}
+
+// typeFields returns the fields of the struct type t, followed by the fields
+// promoted from each of its embedded structs, in order.  Embedded structs may
+// be reached through pointers.  Each field's index is prefixed with index.
+// Struct types on the current embedding path are recorded in visiting so that
+// recursively embedded types terminate.
+func typeFields(t reflect.Type, index []int, visiting map[reflect.Type]bool) []field {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var fields []field
+	var embedded []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		f := field{
+			sf:    sf,
+			owner: t,
+			index: append(append([]int(nil), index...), i),
+		}
+
+		// errors are reported by CheckTags rather than when encoding
+		tag, _ := ParseFieldTag(sf)
+		if tag.Skip {
+			if sf.PkgPath != "" { // unexported
+				f.note = "skipped, unexported"
+				if t := sf.Tag.Get("url"); t != "" && t != "-" {
+					f.note += " despite url tag (see CheckTags)"
+				}
+			} else {
+				f.note = "skipped, tag is \"-\""
+			}
+			fields = append(fields, f)
+			continue
+		}
+
+		f.name, f.opts = tag.Name, tagOptions(tag.Options)
+		if tag.Embedded {
+			// save embedded struct for later processing
+			f.note = "embedded"
+			fields = append(fields, f)
+			if ft := derefType(sf.Type); !visiting[ft] {
+				embedded = append(embedded, f)
+			}
+			continue
+		}
+		fields = append(fields, f)
+	}
+
+	for _, f := range embedded {
+		ft := f.sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		fields = append(fields, typeFields(ft, f.index, visiting)...)
+	}
+	return fields
+}
+
+// fieldByIndex returns the nested field of v with the given index sequence,
+// following pointers to embedded structs.  It reports false if one of those
+// pointers is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// byString sorts string-kinded values.
+type byString []reflect.Value
+
+func (s byString) Len() int           { return len(s) }
+func (s byString) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byString) Less(i, j int) bool { return s[i].String() < s[j].String() }
+
 // valueString returns the string representation of a value.
-func valueString(v reflect.Value, opts tagOptions) string {
+func (c *Config) valueString(v reflect.Value, opts tagOptions) string {
 	for v.Kind() == reflect.Ptr {
 		if v.IsNil() {
 			return ""
@@ -254,9 +1061,36 @@ func valueString(v reflect.Value, opts tagOptions) string {
 		if opts.Contains("unix") {
 			return strconv.FormatInt(t.Unix(), 10)
 		}
+		if opts.Contains("unixmilli") {
+			return strconv.FormatInt(t.UnixMilli(), 10)
+		}
+		if opts.Contains("unixnano") {
+			return strconv.FormatInt(t.UnixNano(), 10)
+		}
+		if opts.Contains("httpdate") {
+			return t.UTC().Format(http.TimeFormat)
+		}
 		return t.Format(time.RFC3339)
 	}
 
+	if v.Type() == percentType {
+		return v.Interface().(Percent).format(opts)
+	}
+
+	if (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) && !v.Type().Implements(stringerType) {
+		if p, ok := opts.Value("prec"); ok {
+			if prec, err := strconv.Atoi(p); err == nil && prec >= 0 {
+				return strconv.FormatFloat(v.Float(), 'f', prec, v.Type().Bits())
+			}
+		}
+		if opts.Contains("noexp") {
+			return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+		}
+		if c.FloatFormat != 0 {
+			return strconv.FormatFloat(v.Float(), c.FloatFormat, c.FloatPrecision, v.Type().Bits())
+		}
+	}
+
 	return fmt.Sprint(v.Interface())
 }
 
@@ -282,6 +1116,10 @@ func isEmptyValue(v reflect.Value) bool {
 		return v.Interface().(time.Time).IsZero()
 	}
 
+	if v.Kind() == reflect.Struct && v.Type().Implements(optionEncoderType) {
+		return v.IsZero()
+	}
+
 	return false
 }
 
@@ -296,6 +1134,31 @@ func parseTag(tag string) (string, tagOptions) {
 	return s[0], s[1:]
 }
 
+// Value returns the value of an option of the form "option=value" in the
+// tagOptions, and whether it was present.
+func (o tagOptions) Value(option string) (string, bool) {
+	for _, s := range o {
+		if strings.HasPrefix(s, option+"=") {
+			return s[len(option)+1:], true
+		}
+	}
+	return "", false
+}
+
+// delimiter returns the delimiter selected by the "comma", "space" or "pipe"
+// option, or 0 if there is none.
+func (o tagOptions) delimiter() byte {
+	switch {
+	case o.Contains("comma"):
+		return ','
+	case o.Contains("space"):
+		return ' '
+	case o.Contains("pipe"):
+		return '|'
+	}
+	return 0
+}
+
 // Contains checks whether the tagOptions contains the specified option.
 func (o tagOptions) Contains(option string) bool {
 	for _, s := range o {
@@ -5,9 +5,12 @@
 package query
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -95,6 +98,25 @@ func TestValues_types(t *testing.T) {
 				"I[]": {"a", "b"},
 			},
 		},
+		{
+			// pointers to slices and maps
+			struct {
+				A *[]string `url:",comma"`
+				B *[]string
+				C *map[string]string
+				D *map[string]string `url:"d"`
+				E *[2]int            `url:",brackets"`
+			}{
+				A: &[]string{"a", "b"},
+				D: &map[string]string{"k": "v"},
+				E: &[2]int{1, 2},
+			},
+			url.Values{
+				"A":    {"a,b"},
+				"d[k]": {"v"},
+				"E[]":  {"1", "2"},
+			},
+		},
 		{
 			// other types
 			struct {
@@ -102,17 +124,29 @@ func TestValues_types(t *testing.T) {
 				B time.Time `url:",unix"`
 				C bool      `url:",int"`
 				D bool      `url:",int"`
+				E time.Time `url:",unixmilli"`
+				F time.Time `url:",unixnano"`
+				G time.Time `url:",unixmilli"`
+				H time.Time `url:",unixmilli"`
 			}{
 				A: time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC),
 				B: time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC),
 				C: true,
 				D: false,
+				E: time.Date(2000, 1, 1, 12, 34, 56, 789000000, time.UTC),
+				F: time.Date(2000, 1, 1, 12, 34, 56, 789000001, time.UTC),
+				G: time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC),
+				H: time.Unix(0, -1),
 			},
 			url.Values{
 				"A": {"2000-01-01T12:34:56Z"},
 				"B": {"946730096"},
 				"C": {"1"},
 				"D": {"0"},
+				"E": {"946730096789"},
+				"F": {"946730096789000001"},
+				"G": {"10413792000000"},
+				"H": {"-1"},
 			},
 		},
 		{
@@ -146,6 +180,22 @@ func TestValues_types(t *testing.T) {
 				"nest[ptr][value]": {"that"},
 			},
 		},
+		{
+			// maps
+			struct {
+				A url.Values          `url:"a"`
+				B map[string]string   `url:"b"`
+				C map[string][]string `url:"c,omitempty"`
+			}{
+				A: url.Values{"x": {"1", "2"}, "y": {"3"}},
+				B: map[string]string{"x": "1"},
+			},
+			url.Values{
+				"a[x]": {"1", "2"},
+				"a[y]": {"3"},
+				"b[x]": {"1"},
+			},
+		},
 		{
 			nil,
 			url.Values{},
@@ -173,20 +223,22 @@ func TestValues_omitEmpty(t *testing.T) {
 		C string  `url:"-"`
 		D string  `url:"omitempty"` // actually named omitempty, not an option
 		E *string `url:",omitempty"`
-	}{E: &str}
+		F string  `url:"-,"` // actually named "-", not skipped
+	}{E: &str, F: "x"}
 
 	v, err := Values(s)
 	if err != nil {
-		t.Errorf("Values(%q) returned error: %v", s, err)
+		t.Errorf("Values(%v) returned error: %v", s, err)
 	}
 
 	want := url.Values{
 		"A":         {""},
 		"omitempty": {""},
+		"-":         {"x"},
 		"E":         {""}, // E is included because the pointer is not empty, even though the string being pointed to is
 	}
 	if !reflect.DeepEqual(want, v) {
-		t.Errorf("Values(%q) returned %v, want %v", s, v, want)
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
 	}
 }
 
@@ -203,6 +255,16 @@ type D struct {
 	C string
 }
 
+type E struct {
+	*B
+	F
+}
+
+type F struct {
+	*D
+	G string
+}
+
 func TestValues_embeddedStructs(t *testing.T) {
 	tests := []struct {
 		in   interface{}
@@ -216,6 +278,19 @@ func TestValues_embeddedStructs(t *testing.T) {
 			D{B: B{C: "bar"}, C: "foo"},
 			url.Values{"C": {"foo", "bar"}},
 		},
+		{
+			// embedded pointers, at several levels
+			E{
+				B: &B{C: "foo"},
+				F: F{D: &D{B: B{C: "baz"}, C: "bar"}, G: "g"},
+			},
+			url.Values{"C": {"foo", "bar", "baz"}, "G": {"g"}},
+		},
+		{
+			// nil embedded pointers are skipped
+			E{F: F{G: "g"}},
+			url.Values{"G": {"g"}},
+		},
 	}
 
 	for i, tt := range tests {
@@ -230,6 +305,59 @@ func TestValues_embeddedStructs(t *testing.T) {
 	}
 }
 
+func TestValues_map(t *testing.T) {
+	in := map[string]interface{}{
+		"q":    "foo",
+		"page": 2,
+		"ids":  []interface{}{1, "2"},
+		"filter": map[string]interface{}{
+			"name": "acme",
+			"addr": map[string]interface{}{"city": "SFO"},
+			"sub":  SubNested{Value: "v"},
+		},
+		"none": nil,
+	}
+	v, err := Values(in)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", in, err)
+	}
+
+	want := url.Values{
+		"q":                  {"foo"},
+		"page":               {"2"},
+		"ids":                {"1", "2"},
+		"filter[name]":       {"acme"},
+		"filter[addr][city]": {"SFO"},
+		"filter[sub][value]": {"v"},
+		"none":               {""},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", in, v, want)
+	}
+
+	var decoded map[string]interface{}
+	data := `{"id":12345678,"page":1000000,"price":0.5,"tags":[null,"x"],"items":[{"sku":"a"},{"sku":"b","qty":2}]}`
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	v, err = Values(decoded)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", decoded, err)
+	}
+	want = url.Values{
+		"id":            {"12345678"},
+		"page":          {"1000000"},
+		"price":         {"0.5"},
+		"tags":          {"", "x"},
+		"items[0][sku]": {"a"},
+		"items[1][sku]": {"b"},
+		"items[1][qty]": {"2"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%s) returned %v, want %v", data, v, want)
+	}
+}
+
 func TestValues_invalidInput(t *testing.T) {
 	_, err := Values("")
 	if err == nil {
@@ -265,6 +393,787 @@ func TestValues_Marshaler(t *testing.T) {
 	}
 }
 
+type ptrEncoded struct {
+	N int
+}
+
+func (p *ptrEncoded) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, fmt.Sprintf("n%d", p.N))
+	return nil
+}
+
+func TestValues_PtrMarshaler(t *testing.T) {
+	s := struct {
+		P ptrEncoded `url:"p"`
+	}{ptrEncoded{1}}
+
+	// not addressable, so P is encoded as a nested struct
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{"p[N]": {"1"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	v, err = Values(&s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", &s, err)
+	}
+	want = url.Values{"p": {"n1"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", &s, v, want)
+	}
+
+	if got, want := Keys(s), []string{"p", "p[N]"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, got, want)
+	}
+	for _, in := range []interface{}{s, &s} {
+		v, _ := Values(in)
+		if got := Filter(v, s); !reflect.DeepEqual(v, got) {
+			t.Errorf("Filter(%v) returned %v, want %v", v, got, v)
+		}
+	}
+}
+
+type color int
+
+func (c color) String() string {
+	return [...]string{"red", "green", "blue"}[c]
+}
+
+func TestValues_Stringer(t *testing.T) {
+	s := struct {
+		A color
+		B []color `url:",comma"`
+	}{2, []color{0, 1}}
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+
+	want := url.Values{
+		"A": {"blue"},
+		"B": {"red,green"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+func TestConfig_trace(t *testing.T) {
+	s := struct {
+		A string `url:"a"`
+		B string `url:",omitempty"`
+		C string `url:"-"`
+		d string
+	}{A: "x"}
+
+	var buf bytes.Buffer
+	c := &Config{Trace: &buf}
+	if _, err := c.Values(s); err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		`.A: key "a" []: "x"`,
+		`.B: key "B" [omitempty]: skipped, empty`,
+		`.C: skipped, tag is "-"`,
+		`.d: skipped, unexported`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("trace output %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestConfig_hashStore(t *testing.T) {
+	s := struct {
+		Q      string   `url:"q"`
+		Filter Nested   `url:"filter,hash"`
+		IDs    []string `url:"ids,hash"`
+	}{
+		Q:      "foo",
+		Filter: Nested{A: SubNested{Value: "x"}},
+		IDs:    []string{"1", "2"},
+	}
+
+	// without HashStore the option has no effect
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if got, want := v["ids"], []string{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values(%v) returned ids %q, want %q", s, got, want)
+	}
+
+	stored := make(map[string]url.Values)
+	c := &Config{HashStore: func(hash string, values url.Values) error {
+		stored[hash] = values
+		return nil
+	}}
+	v, err = c.Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+
+	if got := v.Get("q"); got != "foo" {
+		t.Errorf("Values(%v) returned q %q, want %q", s, got, "foo")
+	}
+	for key, want := range map[string]url.Values{
+		"filter": {"filter[a][value]": {"x"}, "filter[b]": {""}},
+		"ids":    {"ids": {"1", "2"}},
+	} {
+		hash := v[key]
+		if len(hash) != 1 || len(hash[0]) != 64 {
+			t.Errorf("Values(%v) returned %s %q, want a single hash", s, key, hash)
+			continue
+		}
+		if got := stored[hash[0]]; !reflect.DeepEqual(want, got) {
+			t.Errorf("HashStore got %v for %s, want %v", got, key, want)
+		}
+	}
+}
+
+func TestConfig_scope(t *testing.T) {
+	s := struct {
+		Nest Nested            `url:"nest"`
+		M    map[string]string `url:"m"`
+	}{
+		Nest: Nested{A: SubNested{Value: "that"}},
+		M:    map[string]string{"k": "v"},
+	}
+
+	tests := []struct {
+		scope func(string, string) string
+		want  url.Values
+	}{
+		{
+			nil,
+			url.Values{"nest[a][value]": {"that"}, "nest[b]": {""}, "m[k]": {"v"}},
+		},
+		{
+			DotScope,
+			url.Values{"nest.a.value": {"that"}, "nest.b": {""}, "m.k": {"v"}},
+		},
+		{
+			UnderscoreScope,
+			url.Values{"nest_a_value": {"that"}, "nest_b": {""}, "m_k": {"v"}},
+		},
+	}
+	for i, tt := range tests {
+		c := &Config{Scope: tt.scope}
+		v, err := c.Values(s)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, s, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, s, v, tt.want)
+		}
+	}
+}
+
+func TestValues_flatten(t *testing.T) {
+	s := struct {
+		Billing struct {
+			Name    string    `url:"name"`
+			Address SubNested `url:"address"`
+		} `url:"billing,flatten"`
+		Nest Nested `url:"nest"`
+	}{}
+	s.Billing.Name = "acme"
+	s.Billing.Address.Value = "SFO"
+
+	v, err := (&Config{Scope: DotScope}).Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"billing_name":          {"acme"},
+		"billing_address_value": {"SFO"},
+		"nest.a.value":          {""},
+		"nest.b":                {""},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	keys := Keys(s)
+	wantKeys := []string{"billing_name", "billing_address_value", "nest[a][value]", "nest[b]", "nest[b][value]", "nest[ptr]", "nest[ptr][value]"}
+	if !reflect.DeepEqual(wantKeys, keys) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, keys, wantKeys)
+	}
+}
+
+type celsius float64
+
+func (c celsius) String() string {
+	return fmt.Sprintf("%.1fC", float64(c))
+}
+
+func TestConfig_float(t *testing.T) {
+	s := struct {
+		A float64
+		B float32
+		C []float64 `url:",comma"`
+		D celsius
+		E Percent
+	}{-2.751e-06, 1.5, []float64{1, 2.345}, 21, 12.5}
+
+	tests := []struct {
+		format byte
+		prec   int
+		want   url.Values
+	}{
+		{
+			0, 0,
+			url.Values{"A": {"-2.751e-06"}, "B": {"1.5"}, "C": {"1,2.345"}, "D": {"21.0C"}, "E": {"12.5"}},
+		},
+		{
+			'f', 2,
+			url.Values{"A": {"-0.00"}, "B": {"1.50"}, "C": {"1.00,2.35"}, "D": {"21.0C"}, "E": {"12.5"}},
+		},
+		{
+			'f', -1,
+			url.Values{"A": {"-0.000002751"}, "B": {"1.5"}, "C": {"1,2.345"}, "D": {"21.0C"}, "E": {"12.5"}},
+		},
+	}
+	for i, tt := range tests {
+		c := &Config{FloatFormat: tt.format, FloatPrecision: tt.prec}
+		v, err := c.Values(s)
+		if err != nil {
+			t.Errorf("%d. Values(%v) returned error: %v", i, s, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("%d. Values(%v) returned %v, want %v", i, s, v, tt.want)
+		}
+	}
+}
+
+func TestValues_floatOptions(t *testing.T) {
+	s := struct {
+		A float64   `url:",prec=2"`
+		B float32   `url:",prec=0"`
+		C float64   `url:",noexp"`
+		D []float64 `url:",comma,prec=1"`
+		E *float64  `url:",prec=3"`
+		F float64   `url:",prec=x"`
+		G celsius   `url:",prec=3"`
+	}{51.5074, 2.5, 1.5e-07, []float64{1, -0.25}, new(float64), 1e21, 3}
+	want := url.Values{
+		"A": {"51.51"},
+		"B": {"2"},
+		"C": {"0.00000015"},
+		"D": {"1.0,-0.2"},
+		"E": {"0.000"},
+		"F": {"1e+21"},
+		"G": {"3.0C"},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	// field options take precedence over the Config format
+	c := &Config{FloatFormat: 'e', FloatPrecision: 1}
+	v, err = c.Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if got, want := v.Get("A"), "51.51"; got != want {
+		t.Errorf("Values(%v) A = %q, want %q", s, got, want)
+	}
+	if got, want := v.Get("F"), "1.0e+21"; got != want {
+		t.Errorf("Values(%v) F = %q, want %q", s, got, want)
+	}
+}
+
+func TestConfig_sortValues(t *testing.T) {
+	s := struct {
+		A []string
+		B string `url:"A"`
+		C map[string][]int
+	}{
+		A: []string{"b", "c"},
+		B: "a",
+		C: map[string][]int{"y": {3, 1, 2}, "x": {9}},
+	}
+
+	tests := []struct {
+		sort bool
+		want url.Values
+	}{
+		{false, url.Values{"A": {"b", "c", "a"}, "C[x]": {"9"}, "C[y]": {"3", "1", "2"}}},
+		{true, url.Values{"A": {"a", "b", "c"}, "C[x]": {"9"}, "C[y]": {"1", "2", "3"}}},
+	}
+	for _, tt := range tests {
+		c := &Config{SortValues: tt.sort}
+		v, err := c.Values(s)
+		if err != nil {
+			t.Errorf("Values(%v) returned error: %v", s, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("SortValues %v: Values(%v) returned %v, want %v", tt.sort, s, v, tt.want)
+		}
+
+		// encoding a map directly is sorted the same way
+		v, err = c.Values(s.C)
+		if err != nil {
+			t.Errorf("Values(%v) returned error: %v", s.C, err)
+		}
+		if got, want := v["y"], tt.want["C[y]"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("SortValues %v: Values(%v)[y] = %v, want %v", tt.sort, s.C, got, want)
+		}
+	}
+}
+
+func TestConfig_rename(t *testing.T) {
+	type addr struct {
+		City string `url:"city"`
+	}
+	type Meta struct {
+		Source string `url:"source"`
+	}
+	s := struct {
+		Name string `url:"name"`
+		Addr addr   `url:"addr"`
+		Flat addr   `url:"flat,flatten"`
+		Meta
+		Map   map[string]addr `url:"map"`
+		Token string          `url:"token"`
+	}{
+		Name:  "acme",
+		Addr:  addr{"SFO"},
+		Flat:  addr{"NYC"},
+		Meta:  Meta{"web"},
+		Map:   map[string]addr{"a": {"LAX"}},
+		Token: "t",
+	}
+
+	c := &Config{Rename: map[string]string{
+		"Name":      "company",
+		"Addr":      "address",
+		"Addr.City": "town",
+		"Flat.City": "town",
+		"Source":    "src",
+		"Map.City":  "town",
+		"Token":     "-",
+	}}
+	v, err := c.Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"company":       {"acme"},
+		"address[town]": {"SFO"},
+		"flat_town":     {"NYC"},
+		"src":           {"web"},
+		"map[a][city]":  {"LAX"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	// the template placeholders accepted by Expand follow the renamed keys
+	if got, err := c.Expand("/{address[town]}?t={flat_town}", s); err != nil || got != "/SFO?t=NYC" {
+		t.Errorf("Expand returned %q, %v, want %q", got, err, "/SFO?t=NYC")
+	}
+	if _, err := c.Expand("/{name}", s); err == nil {
+		t.Errorf("Expand with original name returned nil error, want error")
+	}
+}
+
+func TestConfig_prefix(t *testing.T) {
+	s := struct {
+		Q    string `url:"q"`
+		User struct {
+			Name string `url:"name"`
+		} `url:"user"`
+		Extra map[string]string `url:"extra"`
+	}{Q: "go", Extra: map[string]string{"a": "1"}}
+	s.User.Name = "acme"
+
+	c := &Config{Prefix: "x_"}
+	v, err := c.Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"x_q":          {"go"},
+		"x_user[name]": {"acme"},
+		"x_extra[a]":   {"1"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	if got, err := c.Expand("/?n={x_user[name]}", s); err != nil || got != "/?n=acme" {
+		t.Errorf("Expand returned %q, %v, want %q", got, err, "/?n=acme")
+	}
+}
+
+func TestConfig_within(t *testing.T) {
+	s := struct {
+		Q    string `url:"q"`
+		IDs  []int  `url:"ids,brackets"`
+		User struct {
+			Name string `url:"name"`
+		} `url:"user"`
+	}{Q: "go", IDs: []int{1, 2}}
+	s.User.Name = "acme"
+
+	c := &Config{Within: "t123"}
+	v, err := c.Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"t123[q]":          {"go"},
+		"t123[ids][]":      {"1", "2"},
+		"t123[user][name]": {"acme"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	// Unscope reverses the encoding
+	direct, _ := Values(s)
+	if got := Unscope(v, "t123"); !reflect.DeepEqual(direct, got) {
+		t.Errorf("Unscope(%v) returned %v, want %v", v, got, direct)
+	}
+
+	// maps are scoped too, and Prefix applies outside the scope
+	c = &Config{Within: "t1", Prefix: "x_", Scope: DotScope}
+	m := map[string]string{"a": "1"}
+	v, err = c.Values(m)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", m, err)
+	}
+	if want := (url.Values{"x_t1.a": {"1"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", m, v, want)
+	}
+	if got, err := c.Expand("?q={x_t1.q}", s); err != nil || got != "?q=go" {
+		t.Errorf("Expand returned %q, %v, want %q", got, err, "?q=go")
+	}
+}
+
+type LineItem struct {
+	Name string `url:"name"`
+	Qty  int    `url:"qty,omitempty"`
+}
+
+func TestValues_group(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				Order string     `url:"order"`
+				Items []LineItem `url:",group"`
+			}{"o1", []LineItem{{"a", 1}, {"b", 2}}},
+			url.Values{"order": {"o1"}, "name": {"a", "b"}, "qty": {"1", "2"}},
+		},
+		{
+			// missing values are empty, keeping the arrays aligned
+			struct {
+				Items []*LineItem `url:",group"`
+			}{[]*LineItem{{"a", 0}, nil, {"c", 3}}},
+			url.Values{"name": {"a", "", "c"}, "qty": {"", "", "3"}},
+		},
+		{
+			// elements are encoded within the enclosing scope
+			struct {
+				Order struct {
+					Items *[2]LineItem `url:",group"`
+				} `url:"order"`
+			}{struct {
+				Items *[2]LineItem `url:",group"`
+			}{&[2]LineItem{{"a", 1}, {"b", 2}}}},
+			url.Values{"order[name]": {"a", "b"}, "order[qty]": {"1", "2"}},
+		},
+		{
+			struct {
+				Items []LineItem  `url:",group,omitempty"`
+				Nil   *[]LineItem `url:",group"`
+			}{},
+			url.Values{},
+		},
+	}
+
+	for _, tt := range tests {
+		v, err := Values(tt.input)
+		if err != nil {
+			t.Errorf("Values(%v) returned error: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("Values(%v) returned %v, want %v", tt.input, v, tt.want)
+		}
+	}
+}
+
+func TestValues_groupErrors(t *testing.T) {
+	type multi struct {
+		Tags []string `url:"tag"`
+	}
+	tests := []interface{}{
+		struct {
+			Items []string `url:",group"`
+		}{[]string{"a"}},
+		struct {
+			Items []multi `url:",group"`
+		}{[]multi{{[]string{"a", "b"}}}},
+	}
+	for _, input := range tests {
+		if v, err := Values(input); err == nil {
+			t.Errorf("Values(%v) returned %v, want error", input, v)
+		}
+	}
+}
+
+type Point struct {
+	Lat, Lng float64
+}
+
+func TestValues_composite(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				A [2]float64 `url:"lat+lng"`
+			}{[2]float64{51.5, -0.1}},
+			url.Values{"lat": {"51.5"}, "lng": {"-0.1"}},
+		},
+		{
+			struct {
+				A Point  `url:"lat+lng,prec=2"`
+				B *Point `url:"x+y"`
+			}{A: Point{1, 2}},
+			url.Values{"lat": {"1.00"}, "lng": {"2.00"}, "x": {""}, "y": {""}},
+		},
+		{
+			struct {
+				Area struct {
+					Range []int `url:"from+to"`
+				} `url:"area"`
+			}{struct {
+				Range []int `url:"from+to"`
+			}{[]int{1, 9}}},
+			url.Values{"area[from]": {"1"}, "area[to]": {"9"}},
+		},
+	}
+
+	for _, tt := range tests {
+		v, err := Values(tt.input)
+		if err != nil {
+			t.Errorf("Values(%v) returned error: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("Values(%v) returned %v, want %v", tt.input, v, tt.want)
+		}
+	}
+
+	errs := []interface{}{
+		struct {
+			A []int `url:"a+b"`
+		}{[]int{1}},
+		struct {
+			A string `url:"a+b"`
+		}{"ab"},
+	}
+	for _, input := range errs {
+		if v, err := Values(input); err == nil {
+			t.Errorf("Values(%v) returned %v, want error", input, v)
+		}
+	}
+}
+
+func TestValues_delimitedObjects(t *testing.T) {
+	type color struct {
+		R int `url:"R"`
+		G int `url:"G"`
+		B int `url:"B,omitempty"`
+	}
+	s := struct {
+		A []string        `url:"a,pipe"`
+		B color           `url:"b,comma"`
+		C *color          `url:"c,pipe"`
+		D map[string]int  `url:"d,space"`
+		E Set[int]        `url:"e,pipe"`
+		F map[string]bool `url:"f,comma"`
+	}{
+		A: []string{"x", "y"},
+		B: color{100, 200, 0},
+		C: &color{1, 2, 3},
+		D: map[string]int{"z": 1, "a": 2},
+		E: NewSet(2, 1),
+	}
+	want := url.Values{
+		"a": {"x|y"},
+		"b": {"R,100,G,200"},
+		"c": {"R|1|G|2|B|3"},
+		"d": {"a 2 z 1"},
+		"e": {"1|2"},
+		"f": {""},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	if got, want := Keys(s), []string{"a", "b", "c", "d", "e", "f"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, got, want)
+	}
+}
+
+func TestScopedValues(t *testing.T) {
+	filter := struct {
+		Status []string `url:"status"`
+		Owner  struct {
+			ID int `url:"id"`
+		} `url:"owner"`
+	}{Status: []string{"open", "new"}}
+	filter.Owner.ID = 7
+	page := struct {
+		Size int `url:"size"`
+	}{20}
+
+	v, err := ScopedValues("filter", filter)
+	if err != nil {
+		t.Errorf("ScopedValues(%v) returned error: %v", filter, err)
+	}
+	p, err := ScopedValues("page", &page)
+	if err != nil {
+		t.Errorf("ScopedValues(%v) returned error: %v", page, err)
+	}
+	for k, vs := range p {
+		v[k] = vs
+	}
+
+	want := "filter%5Bowner%5D%5Bid%5D=7&filter%5Bstatus%5D=open&filter%5Bstatus%5D=new&page%5Bsize%5D=20"
+	if got := v.Encode(); got != want {
+		t.Errorf("ScopedValues returned %q, want %q", got, want)
+	}
+}
+
+func TestConfig_audience(t *testing.T) {
+	s := struct {
+		Q     string `url:"q"`
+		Debug string `url:"debug_info,audiences=internal"`
+		Trace string `url:"trace,audiences=internal|ops"`
+		Inner struct {
+			Host string `url:"host"`
+		} `url:"inner,audiences=ops"`
+	}{Q: "go", Debug: "d", Trace: "t"}
+	s.Inner.Host = "h"
+
+	tests := []struct {
+		audience string
+		want     url.Values
+	}{
+		{"", url.Values{"q": {"go"}}},
+		{"public", url.Values{"q": {"go"}}},
+		{"internal", url.Values{"q": {"go"}, "debug_info": {"d"}, "trace": {"t"}}},
+		{"ops", url.Values{"q": {"go"}, "trace": {"t"}, "inner[host]": {"h"}}},
+	}
+	for _, tt := range tests {
+		c := &Config{Audience: tt.audience}
+		v, err := c.Values(s)
+		if err != nil {
+			t.Errorf("Values(%v) returned error: %v", s, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("Audience %q: Values(%v) returned %v, want %v", tt.audience, s, v, tt.want)
+		}
+	}
+}
+
+type checksummed struct {
+	A string `url:"a"`
+	B string `url:"b"`
+}
+
+func (c checksummed) ExtraValues() url.Values {
+	return url.Values{"sum": {fmt.Sprint(len(c.A) + len(c.B))}}
+}
+
+type derived struct {
+	N int `url:"n"`
+}
+
+func (d *derived) ExtraValues() url.Values {
+	return url.Values{"even": {fmt.Sprint(d.N%2 == 0)}, "n": {"extra"}}
+}
+
+func TestValues_extraValues(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			checksummed{"ab", "c"},
+			url.Values{"a": {"ab"}, "b": {"c"}, "sum": {"3"}},
+		},
+		{
+			struct {
+				C checksummed `url:"c"`
+			}{checksummed{"x", ""}},
+			url.Values{"c[a]": {"x"}, "c[b]": {""}, "c[sum]": {"1"}},
+		},
+		{
+			// pointer receivers are only used for addressable structs
+			derived{2},
+			url.Values{"n": {"2"}},
+		},
+		{
+			&derived{2},
+			url.Values{"n": {"2", "extra"}, "even": {"true"}},
+		},
+	}
+
+	for _, tt := range tests {
+		v, err := Values(tt.input)
+		if err != nil {
+			t.Errorf("Values(%v) returned error: %v", tt.input, err)
+		}
+		if !reflect.DeepEqual(tt.want, v) {
+			t.Errorf("Values(%v) returned %v, want %v", tt.input, v, tt.want)
+		}
+	}
+}
+
+func TestValues_readOnly(t *testing.T) {
+	s := struct {
+		ID    string `url:"id,ro"`
+		Nonce string `url:"nonce,wo"`
+		Inner struct {
+			Echo string `url:"echo,ro"`
+		} `url:"inner"`
+	}{ID: "server", Nonce: "n"}
+	s.Inner.Echo = "e"
+
+	want := url.Values{"nonce": {"n"}}
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
 func TestTagParsing(t *testing.T) {
 	name, opts := parseTag("field,foobar,foo")
 	if name != "field" {
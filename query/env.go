@@ -0,0 +1,81 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// An Env builds url.Values from environment variables, so that a struct
+// describing the parameters of an HTTP query can also be configured from the
+// environment.
+type Env struct {
+	// Prefix is added to the start of each variable name, such as "APP_".
+	Prefix string
+
+	// Name, if non-nil, returns the variable name, without Prefix, for a URL
+	// parameter name.  If nil, EnvName is used.
+	Name func(key string) string
+
+	// Environ, if non-nil, lists the environment as "name=value" strings in
+	// place of os.Environ().
+	Environ []string
+}
+
+// EnvName returns the conventional environment variable name for the URL
+// parameter name key: upper case, with each run of other characters than
+// letters and digits replaced by a single underscore, so that "user[name]"
+// becomes "USER_NAME" and "ids[]" becomes "IDS".
+func EnvName(key string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range key {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			sep = false
+			b.WriteRune(r)
+		} else {
+			sep = true
+		}
+	}
+	return strings.ToUpper(b.String())
+}
+
+// Values returns the parameters listed by Keys(prototype) that have a
+// corresponding environment variable, each with the variable's value as its
+// single value.  Variables that are set to the empty string are included.  Map
+// fields are not read, as their keys cannot be recovered from variable names.
+func (e *Env) Values(prototype interface{}) url.Values {
+	environ := e.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+
+	name := e.Name
+	if name == nil {
+		name = EnvName
+	}
+
+	values := make(url.Values)
+	for _, key := range Keys(prototype) {
+		if strings.Contains(key, mapKey) {
+			continue
+		}
+		if v, ok := env[e.Prefix+name(key)]; ok {
+			values.Add(key, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"q", "Q"},
+		{"user[name]", "USER_NAME"},
+		{"user[addr][city]", "USER_ADDR_CITY"},
+		{"ids[]", "IDS"},
+		{"page-size", "PAGE_SIZE"},
+		{"_x", "X"},
+	}
+	for _, tt := range tests {
+		if got := EnvName(tt.in); got != tt.want {
+			t.Errorf("EnvName(%q) returned %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEnv_Values(t *testing.T) {
+	prototype := struct {
+		Query string `url:"q"`
+		IDs   []int  `url:"ids,comma"`
+		User  struct {
+			Name string `url:"name"`
+		} `url:"user"`
+		Page int `url:"page"`
+	}{}
+
+	e := &Env{
+		Prefix:  "APP_",
+		Environ: []string{"APP_Q=a=b", "APP_IDS=1,2", "APP_USER_NAME=", "Q=other", "APP_PAGE_SIZE=3"},
+	}
+	want := url.Values{"q": {"a=b"}, "ids": {"1,2"}, "user[name]": {""}}
+	if got := e.Values(prototype); !reflect.DeepEqual(want, got) {
+		t.Errorf("Values returned %v, want %v", got, want)
+	}
+
+	e.Name = strings.ToLower
+	e.Environ = []string{"APP_q=x", "APP_Q=y"}
+	want = url.Values{"q": {"x"}}
+	if got := e.Values(prototype); !reflect.DeepEqual(want, got) {
+		t.Errorf("Values with Name returned %v, want %v", got, want)
+	}
+}
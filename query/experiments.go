@@ -0,0 +1,79 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// ExperimentParams lists the default names of A/B test and feature flag
+// override parameters, in the form used by Stripper.
+var ExperimentParams = []string{"ff_*", "exp_*"}
+
+// Experiments extracts A/B test and feature flag overrides, such as
+// "ff_new_checkout=1", from url.Values, so that they are kept apart from the
+// parameters of the request itself.
+type Experiments struct {
+	// Params lists the names of override parameters, in the form used by
+	// Stripper.  If nil, ExperimentParams is used.
+	Params []string
+
+	// Allowed maps the name of each override that may be set to a function
+	// validating its value, or to nil to accept any value.  Overrides that
+	// are not listed are discarded.
+	Allowed map[string]func(value string) error
+}
+
+// Overrides holds experiment overrides by parameter name.
+type Overrides map[string]string
+
+// Bool reports whether the override name is set to a true value, as parsed
+// by strconv.ParseBool.
+func (o Overrides) Bool(name string) bool {
+	b, _ := strconv.ParseBool(o[name])
+	return b
+}
+
+// Extract removes every override parameter from values, and returns the
+// first value of each one that is allowed.  If an allowed override has an
+// invalid value, Extract still removes the override parameters, and returns
+// the valid overrides along with an error naming the invalid ones.
+func (e *Experiments) Extract(values url.Values) (Overrides, error) {
+	params := e.Params
+	if params == nil {
+		params = ExperimentParams
+	}
+	s := &Stripper{Params: params}
+
+	o := make(Overrides)
+	var invalid []string
+	for k, vs := range values {
+		if !s.Match(k) {
+			continue
+		}
+		delete(values, k)
+
+		validate, ok := e.Allowed[k]
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		if validate != nil {
+			if err := validate(vs[0]); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %v", k, err))
+				continue
+			}
+		}
+		o[k] = vs[0]
+	}
+
+	if invalid != nil {
+		sort.Strings(invalid)
+		return o, fmt.Errorf("query: invalid experiment overrides: %v", invalid)
+	}
+	return o, nil
+}
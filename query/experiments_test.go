@@ -0,0 +1,76 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestExperiments_Extract(t *testing.T) {
+	variant := func(s string) error {
+		if s != "a" && s != "b" {
+			return errors.New("unknown variant")
+		}
+		return nil
+	}
+	e := &Experiments{Allowed: map[string]func(string) error{
+		"ff_new_ui":  nil,
+		"exp_layout": variant,
+	}}
+
+	values := url.Values{
+		"q":          {"go"},
+		"ff_new_ui":  {"true", "false"},
+		"ff_unknown": {"1"},
+		"exp_layout": {"b"},
+	}
+	got, err := e.Extract(values)
+	if err != nil {
+		t.Errorf("Extract returned error: %v", err)
+	}
+	want := Overrides{"ff_new_ui": "true", "exp_layout": "b"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Extract returned %v, want %v", got, want)
+	}
+	if want := (url.Values{"q": {"go"}}); !reflect.DeepEqual(want, values) {
+		t.Errorf("Extract left %v, want %v", values, want)
+	}
+	if !got.Bool("ff_new_ui") || got.Bool("exp_layout") || got.Bool("missing") {
+		t.Errorf("Bool returned wrong results for %v", got)
+	}
+
+	values = url.Values{"exp_layout": {"c"}, "ff_new_ui": {"1"}}
+	got, err = e.Extract(values)
+	if err == nil {
+		t.Errorf("Extract with invalid override returned nil error, want error")
+	}
+	if want := (Overrides{"ff_new_ui": "1"}); !reflect.DeepEqual(want, got) {
+		t.Errorf("Extract returned %v, want %v", got, want)
+	}
+	if len(values) != 0 {
+		t.Errorf("Extract left %v, want none", values)
+	}
+}
+
+func TestExperiments_params(t *testing.T) {
+	e := &Experiments{
+		Params:  []string{"x-*"},
+		Allowed: map[string]func(string) error{"x-a": nil, "ff_b": nil},
+	}
+	values := url.Values{"x-a": {"1"}, "ff_b": {"2"}}
+	got, err := e.Extract(values)
+	if err != nil {
+		t.Errorf("Extract returned error: %v", err)
+	}
+	if want := (Overrides{"x-a": "1"}); !reflect.DeepEqual(want, got) {
+		t.Errorf("Extract returned %v, want %v", got, want)
+	}
+	if want := (url.Values{"ff_b": {"2"}}); !reflect.DeepEqual(want, values) {
+		t.Errorf("Extract left %v, want %v", values, want)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// A FieldMask is a list of field paths, such as used by the "update_mask"
+// parameter of Google APIs.  Each path is a dot-separated sequence of URL
+// parameter names, naming a field of a struct and then, optionally, fields of
+// the nested structs within it.  A FieldMask is encoded as a single
+// comma-separated value, e.g:
+//
+// 	"update_mask=user.name,user.addr.city,tags"
+type FieldMask []string
+
+// ParseFieldMask parses a comma-separated list of field paths.  Empty paths
+// are ignored.
+func ParseFieldMask(s string) FieldMask {
+	var m FieldMask
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			m = append(m, p)
+		}
+	}
+	return m
+}
+
+// EncodeValues implements the Encoder interface.
+func (m FieldMask) EncodeValues(key string, v *url.Values) error {
+	v.Add(key, strings.Join(m, ","))
+	return nil
+}
+
+// Validate checks that each path in m names a field of the struct type of v,
+// which may be a struct or a pointer to a struct.
+func (m FieldMask) Validate(v interface{}) error {
+	t := structType(v)
+	if t == nil {
+		return fmt.Errorf("query: FieldMask.Validate() expects struct input. Got %T", v)
+	}
+	for _, p := range m {
+		if _, err := maskFields(t, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply copies the fields named by m from src into dst, leaving all other
+// fields of dst unchanged.  dst must be a pointer to a struct, and src a
+// struct or pointer to a struct of the same type.  Nil pointers in dst are
+// allocated as needed to reach a nested field; a nil pointer in src clears
+// the corresponding field of dst.
+func (m FieldMask) Apply(dst, src interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("query: FieldMask.Apply() expects non-nil struct pointer dst. Got %T", dst)
+	}
+	dv = dv.Elem()
+	sv := reflect.ValueOf(src)
+	if !sv.IsValid() {
+		return fmt.Errorf("query: FieldMask.Apply() expects struct src. Got nil")
+	}
+	for sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	if sv.Type() != dv.Type() {
+		return fmt.Errorf("query: FieldMask.Apply() src type %T does not match dst type %T", src, dst)
+	}
+
+	for _, p := range m {
+		fields, err := maskFields(dv.Type(), p)
+		if err != nil {
+			return err
+		}
+
+		s, d := sv, dv
+		for i, f := range fields {
+			df := allocByIndex(d, f.index)
+			sf, ok := fieldByIndex(s, f.index)
+			if !ok {
+				sf = reflect.Zero(df.Type())
+			}
+			if i == len(fields)-1 {
+				df.Set(sf)
+				break
+			}
+
+			for sf.Kind() == reflect.Ptr {
+				if sf.IsNil() {
+					break
+				}
+				if df.IsNil() {
+					df.Set(reflect.New(df.Type().Elem()))
+				}
+				sf, df = sf.Elem(), df.Elem()
+			}
+			if sf.Kind() == reflect.Ptr {
+				// nil in src, so clear the rest of the path in dst
+				df.Set(sf)
+				break
+			}
+			s, d = sf, df
+		}
+	}
+	return nil
+}
+
+// maskFields returns the fields named by each element of the dot-separated
+// path, starting from the struct type t.
+func maskFields(t reflect.Type, path string) ([]field, error) {
+	var fields []field
+	for _, name := range strings.Split(path, ".") {
+		if t.Kind() != reflect.Struct || t == timeType {
+			return nil, fmt.Errorf("query: field mask path %q: %q is not a struct", path, fields[len(fields)-1].name)
+		}
+
+		found := false
+		for _, f := range cachedFields(t) {
+			if f.note == "" && f.name == name {
+				fields = append(fields, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("query: field mask path %q: no field %q in %v", path, name, t)
+		}
+
+		t = fields[len(fields)-1].sf.Type
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	return fields, nil
+}
+
+// allocByIndex is like fieldByIndex, but allocates nil pointers to embedded
+// structs rather than failing.  v must be settable.
+func allocByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
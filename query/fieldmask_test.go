@@ -0,0 +1,108 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type maskAddr struct {
+	City string `url:"city"`
+	Zip  string `url:"zip"`
+}
+
+type maskUser struct {
+	Name string    `url:"name"`
+	Addr *maskAddr `url:"addr"`
+}
+
+type maskOptions struct {
+	User  maskUser  `url:"user"`
+	Tags  []string  `url:"tags"`
+	Count int       `url:"count"`
+	Mask  FieldMask `url:"update_mask,omitempty"`
+}
+
+func TestFieldMask_encode(t *testing.T) {
+	s := maskOptions{Mask: ParseFieldMask("user.name, user.addr.city,,tags")}
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	if got, want := v["update_mask"], []string{"user.name,user.addr.city,tags"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values(%v) returned update_mask %q, want %q", s, got, want)
+	}
+
+	if got, want := Keys(s), "update_mask"; got[len(got)-1] != want {
+		t.Errorf("Keys(%v) returned %q, want it to end with %q", s, got, want)
+	}
+}
+
+func TestFieldMask_Validate(t *testing.T) {
+	tests := []struct {
+		mask FieldMask
+		ok   bool
+	}{
+		{FieldMask{"user.name", "user.addr.city", "tags", "user"}, true},
+		{FieldMask{"user.email"}, false},
+		{FieldMask{"count.x"}, false},
+		{FieldMask{"Count"}, false},
+	}
+	for i, tt := range tests {
+		err := tt.mask.Validate(&maskOptions{})
+		if (err == nil) != tt.ok {
+			t.Errorf("%d. %q.Validate() returned error %v, want ok=%v", i, tt.mask, err, tt.ok)
+		}
+	}
+}
+
+func TestFieldMask_Apply(t *testing.T) {
+	dst := maskOptions{
+		User:  maskUser{Name: "old", Addr: nil},
+		Tags:  []string{"a"},
+		Count: 1,
+	}
+	src := maskOptions{
+		User:  maskUser{Name: "new", Addr: &maskAddr{City: "SFO", Zip: "1234"}},
+		Tags:  []string{"b"},
+		Count: 2,
+	}
+
+	if err := (FieldMask{"user.addr.city", "tags"}).Apply(&dst, src); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	want := maskOptions{
+		User:  maskUser{Name: "old", Addr: &maskAddr{City: "SFO"}},
+		Tags:  []string{"b"},
+		Count: 1,
+	}
+	if !reflect.DeepEqual(want, dst) {
+		t.Errorf("Apply produced %+v, want %+v", dst, want)
+	}
+
+	// a nil pointer in src clears the field in dst
+	src.User.Addr = nil
+	if err := (FieldMask{"user.addr.zip"}).Apply(&dst, &src); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	if dst.User.Addr != nil {
+		t.Errorf("Apply left user.addr = %+v, want nil", dst.User.Addr)
+	}
+
+	if err := (FieldMask{"nope"}).Apply(&dst, src); err == nil {
+		t.Errorf("Apply with invalid path returned nil error")
+	}
+	if err := (FieldMask{"tags"}).Apply(dst, src); err == nil {
+		t.Errorf("Apply to non-pointer returned nil error")
+	}
+	if err := (FieldMask{"tags"}).Apply(&dst, url.Values{}); err == nil {
+		t.Errorf("Apply from mismatched type returned nil error")
+	}
+	if err := (FieldMask{"tags"}).Apply(&dst, nil); err == nil {
+		t.Errorf("Apply from nil returned nil error")
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"reflect"
+)
+
+// Fingerprint returns a stable hex-encoded SHA-256 hash of the parameters of
+// values produced by fields of prototype that have the "fingerprint" option,
+// or are nested in a struct field that has it.  It is intended as a key for
+// rate limiting or deduplication, such as throttling each distinct search
+// query, and so ignores all other parameters, including volatile ones like
+// timestamps and signatures.  e.g:
+//
+// 	type Search struct {
+// 		Query string `url:"q,fingerprint"`
+// 		Sort  string `url:"sort,fingerprint"`
+// 		Nonce string `url:"nonce"`
+// 		Time  int64  `url:"ts"`
+// 	}
+//
+// The hash does not depend on the order of parameters or their values.
+// Parameters that are absent from values are distinguished from those with
+// an empty value.
+func Fingerprint(values url.Values, prototype interface{}) string {
+	selected := make(url.Values)
+	if t := structType(prototype); t != nil {
+		new(Config).typeKeys(t, "", func(key string, opts tagOptions, _ reflect.Type) {
+			if opts.Contains("fingerprint") {
+				for _, k := range matchingParams(values, key) {
+					selected[k] = values[k]
+				}
+			}
+		}, map[reflect.Type]bool{})
+	}
+
+	sum := sha256.Sum256([]byte(canonicalString(selected)))
+	return hex.EncodeToString(sum[:])
+}
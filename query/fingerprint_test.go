@@ -0,0 +1,54 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	type filter struct {
+		Tag []string `url:"tag"`
+	}
+	prototype := struct {
+		Query  string `url:"q,fingerprint"`
+		Filter filter `url:"f,fingerprint"`
+		Nonce  string `url:"nonce"`
+		Time   int64  `url:"ts"`
+	}{}
+
+	base := Fingerprint(url.Values{
+		"q":      {"go"},
+		"f[tag]": {"a", "b"},
+		"nonce":  {"1"},
+		"ts":     {"100"},
+	}, prototype)
+
+	same := []url.Values{
+		{"q": {"go"}, "f[tag]": {"b", "a"}},
+		{"q": {"go"}, "f[tag]": {"a", "b"}, "nonce": {"2"}, "ts": {"200"}, "utm_source": {"x"}},
+		{"q": {"go"}, "f[tag]": {"a", "b"}, "f[tag][]": {"c"}},
+	}
+	for _, values := range same {
+		if got := Fingerprint(values, prototype); got != base {
+			t.Errorf("Fingerprint(%v) returned %q, want %q", values, got, base)
+		}
+	}
+
+	different := []url.Values{
+		{"q": {"rust"}, "f[tag]": {"a", "b"}},
+		{"q": {"go"}, "f[tag]": {"a"}},
+	}
+	for _, values := range different {
+		if got := Fingerprint(values, prototype); got == base {
+			t.Errorf("Fingerprint(%v) returned %q, want a different fingerprint", values, got)
+		}
+	}
+
+	if Fingerprint(url.Values{"q": {""}}, prototype) == Fingerprint(url.Values{}, prototype) {
+		t.Errorf("Fingerprint does not distinguish empty and absent parameters")
+	}
+}
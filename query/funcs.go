@@ -0,0 +1,67 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+)
+
+// FuncMap returns template functions for building links from parameter
+// structs, for use with the Funcs method of html/template or text/template
+// templates:
+//
+// 	queryParams v
+// 		Returns the encoded query string of v, without a leading "?".
+// 	appendQuery base v
+// 		Returns the URL base with the parameters of v added to its query,
+// 		replacing any of the same name.
+//
+// e.g:
+//
+// 	<a href="/search?{{queryParams .Opts}}">search</a>
+// 	<a href="{{appendQuery .Next .Opts}}">next</a>
+//
+// Both return a template.URL, so that html/template does not escape the "&"
+// and "=" between parameters a second time.  appendQuery returns an error if
+// base has a scheme other than http or https, so that such links stay safe.
+func FuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"queryParams": queryParams,
+		"appendQuery": appendQuery,
+	}
+}
+
+func queryParams(v interface{}) (template.URL, error) {
+	values, err := Values(v)
+	if err != nil {
+		return "", err
+	}
+	return template.URL(values.Encode()), nil
+}
+
+func appendQuery(base string, v interface{}) (template.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "", "http", "https":
+	default:
+		return "", fmt.Errorf("query: appendQuery() unsafe URL scheme %q", u.Scheme)
+	}
+
+	values, err := Values(v)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k, vs := range values {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+	return template.URL(u.String()), nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestFuncMap(t *testing.T) {
+	opts := struct {
+		Query string `url:"q"`
+		Page  int    `url:"page"`
+	}{`"a"&b`, 2}
+
+	tests := []struct {
+		tmpl string
+		data interface{}
+		want string
+	}{
+		{
+			`<a href="/search?{{queryParams .}}">`,
+			opts,
+			`<a href="/search?page=2&amp;q=%22a%22%26b">`,
+		},
+		{
+			`<a href="{{appendQuery "/search?page=1&sort=new" .}}">`,
+			opts,
+			`<a href="/search?page=2&amp;q=%22a%22%26b&amp;sort=new">`,
+		},
+		{
+			`{{appendQuery "https://example.com/" .}}`,
+			struct{ A string }{"x"},
+			`https://example.com/?A=x`,
+		},
+	}
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(tt.tmpl))
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, tt.data); err != nil {
+			t.Errorf("Execute(%q) returned error: %v", tt.tmpl, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("Execute(%q) returned %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+
+	errs := []string{
+		`{{appendQuery "javascript:alert(1)" .}}`,
+		`{{appendQuery "%zz" .}}`,
+		`{{queryParams 1}}`,
+	}
+	for _, s := range errs {
+		tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(s))
+		if err := tmpl.Execute(new(bytes.Buffer), opts); err == nil {
+			t.Errorf("Execute(%q) returned nil error, want error", s)
+		}
+	}
+}
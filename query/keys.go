@@ -0,0 +1,293 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Keys returns the URL parameter names that Values may produce for the struct
+// type of v, in field order and without duplicates.  v may be a struct or a
+// pointer to a struct, and need not be populated; only its type is examined.
+// Keys returns nil if v is not a struct.
+//
+// Nested struct fields are reported with their full scoped names, such as
+// "user[addr][city]", and slice fields with the "brackets" option are
+// reported with the "[]" suffix.  Map fields are reported as a pattern such as
+// "extra[*]", in which "*" stands for any map key.  Fields whose type
+// implements Encoder are reported under their own name, although their
+// EncodeValues method is free to produce other keys.  If only a pointer to the
+// field's type implements Encoder, the field is reported both under its own
+// name and as it is encoded when Values is passed a struct value, which leaves
+// the field unaddressable.  Fields with the "hash" option are also reported
+// under their own name, which is used for the hash.  Fields with the "ro"
+// option are reported too, as they remain parameters of the request, although
+// Values never encodes them.  Recursive struct types are expanded only once.
+func Keys(v interface{}) []string {
+	t := structType(v)
+	if t == nil {
+		return nil
+	}
+
+	var keys []string
+	seen := make(map[string]bool)
+	new(Config).typeKeys(t, "", func(key string, _ tagOptions, _ reflect.Type) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}, map[reflect.Type]bool{})
+	return keys
+}
+
+// inheritedOptions lists the options that apply to every field nested in a
+// struct field that has them.
+var inheritedOptions = []string{"secret", "fingerprint"}
+
+// mapKey stands for any map key in the parameter names reported by typeKeys
+// for map fields, such as "extra[*]".
+const mapKey = "*"
+
+// matchingParams returns the names of the parameters in values that are
+// produced by key, a parameter name reported by typeKeys.  That is key itself,
+// or if key is the pattern of a map field, the names it matches, sorted.
+func matchingParams(values url.Values, key string) []string {
+	prefix, suffix, ok := strings.Cut(key, mapKey)
+	if !ok {
+		if _, ok := values[key]; ok {
+			return []string{key}
+		}
+		return nil
+	}
+	var names []string
+	for k := range values {
+		if matchKey(prefix, suffix, k) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchKey reports whether the parameter name param is that of a map field
+// whose pattern is prefix+mapKey+suffix, for a non-empty map key.
+func matchKey(prefix, suffix, param string) bool {
+	return len(param) > len(prefix)+len(suffix) && strings.HasPrefix(param, prefix) && strings.HasSuffix(param, suffix)
+}
+
+// inherit returns add, wrapped to also report those of the inheritedOptions
+// that are in opts, the options of a field nesting other fields.
+func inherit(opts tagOptions, add func(string, tagOptions, reflect.Type)) func(string, tagOptions, reflect.Type) {
+	var inherited tagOptions
+	for _, o := range inheritedOptions {
+		if opts.Contains(o) {
+			inherited = append(inherited, o)
+		}
+	}
+	if inherited == nil {
+		return add
+	}
+	return func(key string, o tagOptions, t reflect.Type) {
+		add(key, append(append(tagOptions(nil), inherited...), o...), t)
+	}
+}
+
+// rootKeys calls add with each URL parameter name that the struct type typ
+// may produce when encoded with c, including c.Within and c.Prefix, along
+// with the options and type of the field producing it.
+func (c *Config) rootKeys(typ reflect.Type, add func(string, tagOptions, reflect.Type)) {
+	c.typeKeys(typ, c.Within, func(key string, opts tagOptions, t reflect.Type) {
+		add(c.Prefix+key, opts, t)
+	}, map[reflect.Type]bool{})
+}
+
+// typeKeys calls add with each URL parameter name that the struct type typ
+// may produce under scope when encoded with c, along with the options and type
+// of the field producing it.
+// Fields nested in a struct or group field with one of the inheritedOptions
+// are reported with that option too.
+// Types on the current path are recorded in visiting so that recursive types
+// terminate.
+func (c *Config) typeKeys(typ reflect.Type, scope string, add func(string, tagOptions, reflect.Type), visiting map[reflect.Type]bool) {
+	if visiting[typ] {
+		return
+	}
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	for _, f := range cachedFields(typ) {
+		if f.note != "" {
+			continue
+		}
+
+		name, omitted := c.fieldName(f)
+		if omitted {
+			continue
+		}
+		opts := f.opts
+		if names := compositeNames(name); names != nil {
+			for _, n := range names {
+				if scope != "" {
+					n = c.scoped(scope, n)
+				}
+				add(n, opts, f.sf.Type)
+			}
+			continue
+		}
+		if scope != "" {
+			name = c.scoped(scope, name)
+		}
+
+		ft := f.sf.Type
+		if opts.Contains("group") {
+			et := derefType(ft)
+			if (et.Kind() == reflect.Slice || et.Kind() == reflect.Array) && derefType(et.Elem()).Kind() == reflect.Struct {
+				nested := *c
+				nested.path = c.fieldPath(f)
+				nested.typeKeys(derefType(et.Elem()), scope, inherit(opts, add), visiting)
+				continue
+			}
+		}
+
+		if opts.Contains("hash") {
+			// encoded as a single hash when Config.HashStore is set
+			add(name, opts, ft)
+		}
+
+		if ft.Implements(optionEncoderType) {
+			et := ft
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			for _, key := range reflect.Zero(et).Interface().(optionEncoder).keys(name, opts) {
+				add(key, opts, ft)
+			}
+			continue
+		}
+
+		if ft.Implements(encoderType) {
+			add(name, opts, ft)
+			continue
+		}
+		if reflect.PtrTo(ft).Implements(encoderType) {
+			// EncodeValues is only used if the field is addressable, as when
+			// a pointer is passed to Values, so report the other form too
+			add(name, opts, ft)
+		}
+
+		if ft.Kind() == reflect.Ptr {
+			switch ft.Elem().Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				ft = ft.Elem()
+			}
+		}
+
+		if ft.Kind() == reflect.Map && ft.Key().Kind() == reflect.String && opts.delimiter() == 0 {
+			add(c.scoped(name, mapKey), opts, ft)
+			continue
+		}
+
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			if opts.delimiter() == 0 && opts.Contains("brackets") {
+				name = name + "[]"
+			}
+			add(name, opts, ft)
+			continue
+		}
+
+		ptr := false
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			ptr = true
+		}
+
+		if ft.Kind() == reflect.Struct && ft != timeType && opts.delimiter() == 0 {
+			if ptr {
+				// a nil pointer is encoded as an empty value
+				add(name, opts, ft)
+			}
+			nested := *c
+			nested.path = c.fieldPath(f)
+			if opts.Contains("flatten") {
+				nested.Scope = UnderscoreScope
+			}
+			nested.typeKeys(ft, name, inherit(opts, add), visiting)
+			continue
+		}
+
+		add(name, opts, ft)
+	}
+}
+
+// StripPrefix returns a copy of values containing only the parameters whose
+// names begin with prefix, with prefix removed from their names.  It reverses
+// the naming of Config.Prefix.
+func StripPrefix(values url.Values, prefix string) url.Values {
+	stripped := make(url.Values)
+	for k, vs := range values {
+		if strings.HasPrefix(k, prefix) {
+			stripped[k[len(prefix):]] = append([]string(nil), vs...)
+		}
+	}
+	return stripped
+}
+
+// Unscope returns a copy of values containing only the parameters within the
+// bracket scope named scope, such as "t123[q]", with that scope removed from
+// their names, so that "t123[user][name]" becomes "user[name]".  It reverses
+// the naming of Config.Within when Config.Scope is BracketScope.
+func Unscope(values url.Values, scope string) url.Values {
+	unscoped := make(url.Values)
+	for k, vs := range values {
+		if !strings.HasPrefix(k, scope+"[") {
+			continue
+		}
+		rest := k[len(scope)+1:]
+		i := strings.IndexByte(rest, ']')
+		if i < 0 {
+			continue
+		}
+		name := rest[:i] + rest[i+1:]
+		unscoped[name] = append(unscoped[name], vs...)
+	}
+	return unscoped
+}
+
+// Scopes returns the sorted, distinct names of the outermost bracket scopes
+// of the parameters in values, such as "t123" for "t123[q]".  Parameters
+// without a scope, or with an empty one such as "ids[]", are ignored.
+func Scopes(values url.Values) []string {
+	seen := make(map[string]bool)
+	var scopes []string
+	for k := range values {
+		i := strings.IndexByte(k, '[')
+		if i <= 0 || strings.HasPrefix(k[i:], "[]") {
+			continue
+		}
+		if s := k[:i]; !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// Filter returns a copy of values containing only the parameters whose names
+// are listed by Keys(prototype), or match the pattern listed for a map field.
+// It is useful for forwarding a client's query string to another service
+// without passing along unrelated parameters.
+func Filter(values url.Values, prototype interface{}) url.Values {
+	filtered := make(url.Values)
+	for _, key := range Keys(prototype) {
+		for _, k := range matchingParams(values, key) {
+			filtered[k] = append([]string(nil), values[k]...)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,174 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recursive struct {
+	Name string     `url:"name"`
+	Next *recursive `url:"next"`
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want []string
+	}{
+		{
+			struct {
+				A string `url:"a"`
+				B []int  `url:"b,brackets"`
+				C []int  `url:"c,comma,brackets"`
+				D time.Time
+				E string `url:"-"`
+				f string
+				G EncodedArgs `url:"arg"`
+				H string      `url:"a"`
+				I *[]int      `url:"i,brackets"`
+			}{},
+			[]string{"a", "b[]", "c", "D", "arg", "i[]"},
+		},
+		{
+			&struct {
+				Nest Nested `url:"nest"`
+			}{},
+			[]string{"nest[a][value]", "nest[b]", "nest[b][value]", "nest[ptr]", "nest[ptr][value]"},
+		},
+		{
+			D{},
+			[]string{"C"},
+		},
+		{
+			recursive{},
+			[]string{"name", "next"},
+		},
+		{
+			struct {
+				Order string      `url:"order"`
+				Items []*LineItem `url:",group"`
+				Tags  []string    `url:"tag,group"`
+			}{},
+			[]string{"order", "name", "qty", "tag"},
+		},
+		{
+			struct {
+				Loc  Point `url:"lat+lng"`
+				Area struct {
+					Range []int `url:"from+to"`
+				} `url:"area"`
+			}{},
+			[]string{"lat", "lng", "area[from]", "area[to]"},
+		},
+		{
+			struct {
+				Extra  map[string]string             `url:"extra"`
+				Opt    *map[string]int               `url:"opt"`
+				Pairs  map[string]string             `url:"pairs,comma"`
+				Nested struct{ M map[string]string } `url:"n"`
+			}{},
+			[]string{"extra[*]", "opt[*]", "pairs", "n[M][*]"},
+		},
+		{"", nil},
+		{nil, nil},
+	}
+
+	for i, tt := range tests {
+		got := Keys(tt.in)
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("%d. Keys(%#v) returned %q, want %q", i, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	values := url.Values{
+		"q":           {"foo"},
+		"ids[]":       {"1", "2"},
+		"ids":         {"3"},
+		"utm_source":  {"mail"},
+		"extra[a]":    {"x"},
+		"extra[b][c]": {"y"},
+		"extra[]":     {"z"},
+		"extra":       {"w"},
+	}
+	prototype := struct {
+		Query string            `url:"q"`
+		IDs   []int             `url:"ids,brackets"`
+		Page  int               `url:"page"`
+		Extra map[string]string `url:"extra"`
+	}{}
+
+	got := Filter(values, prototype)
+	want := url.Values{
+		"q":           {"foo"},
+		"ids[]":       {"1", "2"},
+		"extra[a]":    {"x"},
+		"extra[b][c]": {"y"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Filter(%v) returned %v, want %v", values, got, want)
+	}
+
+	got["q"][0] = "bar"
+	if values.Get("q") != "foo" {
+		t.Errorf("Filter modified its input values")
+	}
+}
+
+func TestStripPrefix(t *testing.T) {
+	values := url.Values{
+		"x_q":          {"foo"},
+		"x_user[name]": {"acme"},
+		"q":            {"bar"},
+	}
+	got := StripPrefix(values, "x_")
+	want := url.Values{
+		"q":          {"foo"},
+		"user[name]": {"acme"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("StripPrefix(%v) returned %v, want %v", values, got, want)
+	}
+}
+
+func TestUnscope(t *testing.T) {
+	values := url.Values{
+		"t1[q]":          {"foo"},
+		"t1[ids][]":      {"1", "2"},
+		"t1[user][name]": {"acme"},
+		"t12[q]":         {"bar"},
+		"t1":             {"x"},
+		"q":              {"baz"},
+	}
+	got := Unscope(values, "t1")
+	want := url.Values{
+		"q":          {"foo"},
+		"ids[]":      {"1", "2"},
+		"user[name]": {"acme"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Unscope(%v) returned %v, want %v", values, got, want)
+	}
+}
+
+func TestScopes(t *testing.T) {
+	values := url.Values{
+		"t2[q]":       {"foo"},
+		"t1[q]":       {"foo"},
+		"t1[user][a]": {"b"},
+		"ids[]":       {"1"},
+		"q":           {"baz"},
+	}
+	got := Scopes(values)
+	want := []string{"t1", "t2"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Scopes(%v) returned %v, want %v", values, got, want)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Links holds the URLs of the pages around the current page of a paginated
+// list.  Each is empty if there is no such page, or it is not known.
+type Links struct {
+	First, Prev, Next, Last string
+}
+
+// Header returns l as the value of an HTTP Link header (see RFC 8288), with
+// relation types "first", "prev", "next" and "last".  Empty links are left
+// out, and Header returns "" if there are none.
+func (l Links) Header() string {
+	var parts []string
+	for _, link := range []struct{ rel, url string }{
+		{"first", l.First},
+		{"prev", l.Prev},
+		{"next", l.Next},
+		{"last", l.Last},
+	} {
+		if link.url != "" {
+			parts = append(parts, "<"+link.url+`>; rel="`+link.rel+`"`)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PageLinks returns the Links for a list whose current page and filters are
+// described by the struct v, in which the integer field encoded as the URL
+// parameter param holds the page number, counting from 1.  Each link is base
+// with its query replaced by the encoding of a copy of v whose page field is
+// set accordingly.  v itself is not modified.  If last is not positive, the
+// number of pages is taken to be unknown, so Last is left empty and Next is
+// always set.
+//
+// A nil or zero page field is taken to be the first page.
+func PageLinks(base *url.URL, v interface{}, param string, last int) (Links, error) {
+	sv, f, err := linkField(v, param)
+	if err != nil {
+		return Links{}, err
+	}
+
+	page := 1
+	if pv, ok := fieldByIndex(sv, f.index); ok {
+		for pv.Kind() == reflect.Ptr && !pv.IsNil() {
+			pv = pv.Elem()
+		}
+		switch pv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			page = int(pv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			page = int(pv.Uint())
+		case reflect.Ptr:
+			// nil, so the first page
+		default:
+			return Links{}, fmt.Errorf("query: PageLinks() page field %v is not an integer", f)
+		}
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	var l Links
+	if l.First, err = linkURL(base, sv, f, 1); err != nil {
+		return Links{}, err
+	}
+	if page > 1 {
+		if l.Prev, err = linkURL(base, sv, f, page-1); err != nil {
+			return Links{}, err
+		}
+	}
+	if last <= 0 || page < last {
+		if l.Next, err = linkURL(base, sv, f, page+1); err != nil {
+			return Links{}, err
+		}
+	}
+	if last > 0 {
+		if l.Last, err = linkURL(base, sv, f, last); err != nil {
+			return Links{}, err
+		}
+	}
+	return l, nil
+}
+
+// CursorLinks returns the Links for a list whose current position and
+// filters are described by the struct v, in which the string field encoded as
+// the URL parameter param holds an opaque cursor.  First links to v with the
+// cursor cleared, and Prev and Next to v with the cursor set to prev and next
+// respectively, unless they are empty.  Last is always empty.  v itself is
+// not modified.
+func CursorLinks(base *url.URL, v interface{}, param string, prev, next string) (Links, error) {
+	sv, f, err := linkField(v, param)
+	if err != nil {
+		return Links{}, err
+	}
+
+	var l Links
+	if l.First, err = linkURL(base, sv, f, ""); err != nil {
+		return Links{}, err
+	}
+	if prev != "" {
+		if l.Prev, err = linkURL(base, sv, f, prev); err != nil {
+			return Links{}, err
+		}
+	}
+	if next != "" {
+		if l.Next, err = linkURL(base, sv, f, next); err != nil {
+			return Links{}, err
+		}
+	}
+	return l, nil
+}
+
+// linkField returns the struct value of v and its field encoded as param.
+func linkField(v interface{}, param string) (reflect.Value, field, error) {
+	sv := reflect.ValueOf(v)
+	for sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Struct {
+		return sv, field{}, fmt.Errorf("query: links expect struct input. Got %T", v)
+	}
+	for _, f := range cachedFields(sv.Type()) {
+		if f.note == "" && f.name == param {
+			return sv, f, nil
+		}
+	}
+	return sv, field{}, fmt.Errorf("query: no field encoded as %q in %v", param, sv.Type())
+}
+
+// linkURL returns base with its query replaced by the encoding of a copy of
+// the struct sv with its field f set to value, which is either an int or a
+// string.  An empty string sets the field to its zero value.
+func linkURL(base *url.URL, sv reflect.Value, f field, value interface{}) (string, error) {
+	cp := reflect.New(sv.Type()).Elem()
+	cp.Set(sv)
+	fv := cloneByIndex(cp, f.index)
+
+	if s, ok := value.(string); ok && s == "" {
+		fv.Set(reflect.Zero(fv.Type()))
+	} else {
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(reflect.New(fv.Type().Elem()))
+			fv = fv.Elem()
+		}
+		switch x := value.(type) {
+		case int:
+			switch fv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				fv.SetInt(int64(x))
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				fv.SetUint(uint64(x))
+			default:
+				return "", fmt.Errorf("query: cannot set page field %v", f)
+			}
+		case string:
+			if fv.Kind() != reflect.String {
+				return "", fmt.Errorf("query: cannot set cursor field %v", f)
+			}
+			fv.SetString(x)
+		}
+	}
+
+	values, err := Values(cp.Interface())
+	if err != nil {
+		return "", err
+	}
+	u := *base
+	u.RawQuery = values.Encode()
+	return u.String(), nil
+}
+
+// cloneByIndex is like allocByIndex, but also replaces each non-nil pointer
+// to an embedded struct along the way by a pointer to a copy, so that setting
+// the returned field does not modify values shared with other structs.
+func cloneByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			p := reflect.New(v.Type().Elem())
+			if !v.IsNil() {
+				p.Elem().Set(v.Elem())
+			}
+			v.Set(p)
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
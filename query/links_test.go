@@ -0,0 +1,119 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+type Paging struct {
+	Page  int `url:"page,omitempty"`
+	Limit int `url:"limit,omitempty"`
+}
+
+func TestPageLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/items?ignored=1")
+	type list struct {
+		Q string `url:"q"`
+		*Paging
+	}
+
+	tests := []struct {
+		page int
+		last int
+		want Links
+	}{
+		{
+			2, 3,
+			Links{
+				First: "https://example.com/items?limit=10&page=1&q=go",
+				Prev:  "https://example.com/items?limit=10&page=1&q=go",
+				Next:  "https://example.com/items?limit=10&page=3&q=go",
+				Last:  "https://example.com/items?limit=10&page=3&q=go",
+			},
+		},
+		{
+			0, 1,
+			Links{
+				First: "https://example.com/items?limit=10&page=1&q=go",
+				Last:  "https://example.com/items?limit=10&page=1&q=go",
+			},
+		},
+		{
+			5, 0,
+			Links{
+				First: "https://example.com/items?limit=10&page=1&q=go",
+				Prev:  "https://example.com/items?limit=10&page=4&q=go",
+				Next:  "https://example.com/items?limit=10&page=6&q=go",
+			},
+		},
+	}
+	for _, tt := range tests {
+		s := list{"go", &Paging{tt.page, 10}}
+		got, err := PageLinks(base, s, "page", tt.last)
+		if err != nil {
+			t.Errorf("PageLinks(page %d, last %d) returned error: %v", tt.page, tt.last, err)
+		}
+		if got != tt.want {
+			t.Errorf("PageLinks(page %d, last %d) returned %+v, want %+v", tt.page, tt.last, got, tt.want)
+		}
+		if s.Page != tt.page {
+			t.Errorf("PageLinks modified its input: page %d, want %d", s.Page, tt.page)
+		}
+	}
+}
+
+func TestCursorLinks(t *testing.T) {
+	base, _ := url.Parse("/events")
+	s := struct {
+		Cursor *string `url:"cursor"`
+		Kind   string  `url:"kind"`
+	}{Kind: "a b"}
+
+	got, err := CursorLinks(base, s, "cursor", "", "abc")
+	if err != nil {
+		t.Errorf("CursorLinks returned error: %v", err)
+	}
+	want := Links{
+		First: "/events?cursor=&kind=a+b",
+		Next:  "/events?cursor=abc&kind=a+b",
+	}
+	if got != want {
+		t.Errorf("CursorLinks returned %+v, want %+v", got, want)
+	}
+}
+
+func TestLinks_errors(t *testing.T) {
+	base := &url.URL{Path: "/"}
+	s := struct {
+		Page string `url:"page"`
+		N    int    `url:"n"`
+	}{}
+
+	if _, err := PageLinks(base, s, "missing", 1); err == nil {
+		t.Errorf("PageLinks with missing field returned nil error, want error")
+	}
+	if _, err := PageLinks(base, s, "page", 1); err == nil {
+		t.Errorf("PageLinks with string field returned nil error, want error")
+	}
+	if _, err := CursorLinks(base, s, "n", "", "x"); err == nil {
+		t.Errorf("CursorLinks with int field returned nil error, want error")
+	}
+	if _, err := PageLinks(base, 1, "page", 1); err == nil {
+		t.Errorf("PageLinks with int input returned nil error, want error")
+	}
+}
+
+func TestLinks_Header(t *testing.T) {
+	l := Links{First: "/a?page=1", Next: "/a?page=3"}
+	want := `</a?page=1>; rel="first", </a?page=3>; rel="next"`
+	if got := l.Header(); got != want {
+		t.Errorf("Header() returned %q, want %q", got, want)
+	}
+	if got := (Links{}).Header(); got != "" {
+		t.Errorf("Header() of empty Links returned %q, want empty", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A Format is a wire format that Marshal can produce.
+type Format int
+
+const (
+	// FormatQuery is a URL query string, without the leading "?".
+	FormatQuery Format = iota
+
+	// FormatForm is an "application/x-www-form-urlencoded" request body.
+	FormatForm
+
+	// FormatJSON is a JSON document, as produced by encoding/json.
+	FormatJSON
+)
+
+// String returns the name of f.
+func (f Format) String() string {
+	switch f {
+	case FormatQuery:
+		return "query"
+	case FormatForm:
+		return "form"
+	case FormatJSON:
+		return "json"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ContentType returns the media type of a request body in format f, or "" for
+// FormatQuery, which is not sent as a body.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatForm:
+		return "application/x-www-form-urlencoded"
+	case FormatJSON:
+		return "application/json"
+	}
+	return ""
+}
+
+// Marshal returns the encoding of v in format f, so that a client can send
+// the same struct as a query string, a form body or JSON.  The query and form
+// formats are the url.Values encoding of v, as returned by Values, and are
+// identical.  The JSON format is delegated to encoding/json, and so follows
+// "json" struct tags rather than "url" tags.
+func Marshal(v interface{}, f Format) ([]byte, error) {
+	switch f {
+	case FormatQuery, FormatForm:
+		values, err := Values(v)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(values.Encode()), nil
+	case FormatJSON:
+		return json.Marshal(v)
+	}
+	return nil, fmt.Errorf("query: Marshal() unknown format %v", f)
+}
@@ -0,0 +1,62 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	s := struct {
+		Query string `url:"q" json:"query"`
+		IDs   []int  `url:"id" json:"ids"`
+	}{"a b", []int{1, 2}}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatQuery, "id=1&id=2&q=a+b"},
+		{FormatForm, "id=1&id=2&q=a+b"},
+		{FormatJSON, `{"query":"a b","ids":[1,2]}`},
+	}
+	for _, tt := range tests {
+		got, err := Marshal(s, tt.format)
+		if err != nil {
+			t.Errorf("Marshal(%v, %v) returned error: %v", s, tt.format, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%v, %v) returned %q, want %q", s, tt.format, got, tt.want)
+		}
+	}
+
+	if _, err := Marshal(s, Format(9)); err == nil {
+		t.Errorf("Marshal with unknown format returned nil error, want error")
+	}
+	if _, err := Marshal(1, FormatQuery); err == nil {
+		t.Errorf("Marshal(1, FormatQuery) returned nil error, want error")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		format      Format
+		name        string
+		contentType string
+	}{
+		{FormatQuery, "query", ""},
+		{FormatForm, "form", "application/x-www-form-urlencoded"},
+		{FormatJSON, "json", "application/json"},
+		{Format(9), "Format(9)", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.format.String(); got != tt.name {
+			t.Errorf("String() returned %q, want %q", got, tt.name)
+		}
+		if got := tt.format.ContentType(); got != tt.contentType {
+			t.Errorf("%v.ContentType() returned %q, want %q", tt.format, got, tt.contentType)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A Money is an amount of money in a given currency, held exactly as an
+// integer number of the currency's minor units.
+//
+// Money values default to encoding as a single value combining the amount and
+// currency, such as "19.99USD".  Including the "currency=name" option encodes
+// just the amount, such as "19.99", and the currency code as a separate URL
+// parameter with the given name.  e.g:
+//
+// 	// Field appears as "price=19.99USD".
+// 	Field Money `url:"price"`
+//
+// 	// Field appears as "amount=19.99&currency=USD".
+// 	Field Money `url:"amount,currency=currency"`
+type Money struct {
+	Amount   int64  // in minor units, such as cents
+	Currency string // ISO 4217 currency code, such as "USD"
+}
+
+// minorDigits lists the currencies whose minor unit is not one hundredth of
+// the major unit, with the number of decimal digits they use.
+var minorDigits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// digits returns the number of decimal digits used by currency.
+func digits(currency string) int {
+	if d, ok := minorDigits[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// validCurrency reports whether code is formed like an ISO 4217 code.
+func validCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// AmountString returns the amount of m in major units, using the number of
+// decimal digits of its currency, such as "19.99" or "-0.50".
+func (m Money) AmountString() string {
+	d := digits(m.Currency)
+	s := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if m.Amount < 0 {
+		sign, s = "-", s[1:]
+	}
+	if d == 0 {
+		return sign + s
+	}
+	for len(s) <= d {
+		s = "0" + s
+	}
+	return sign + s[:len(s)-d] + "." + s[len(s)-d:]
+}
+
+// String returns m with its amount followed by its currency, such as
+// "19.99USD".
+func (m Money) String() string {
+	return m.AmountString() + m.Currency
+}
+
+// encodeValues adds m to values as described for Money.
+func (m Money) encodeValues(_ *Config, values url.Values, name string, opts tagOptions) error {
+	if !validCurrency(m.Currency) {
+		return fmt.Errorf("query: invalid currency %q for %s", m.Currency, name)
+	}
+	if key, ok := opts.Value("currency"); ok {
+		values.Add(name, m.AmountString())
+		values.Add(key, m.Currency)
+		return nil
+	}
+	values.Add(name, m.String())
+	return nil
+}
+
+func (m Money) keys(name string, opts tagOptions) []string {
+	if key, ok := opts.Value("currency"); ok {
+		return []string{name, key}
+	}
+	return []string{name}
+}
+
+// ParseMoney parses an amount of money in major units, such as "19.99".  If
+// currency is empty, s must end in a currency code, such as "19.99USD".
+// Amounts may not use more decimal digits than the currency allows.
+func ParseMoney(s, currency string) (Money, error) {
+	amount := s
+	if currency == "" && len(s) > 3 {
+		amount, currency = s[:len(s)-3], s[len(s)-3:]
+	}
+	if !validCurrency(currency) {
+		return Money{}, fmt.Errorf("query: invalid currency %q in %q", currency, s)
+	}
+
+	neg := strings.HasPrefix(amount, "-")
+	if neg {
+		amount = amount[1:]
+	}
+	ip, fp := amount, ""
+	if i := strings.IndexByte(amount, '.'); i >= 0 {
+		ip, fp = amount[:i], amount[i+1:]
+	}
+	d := digits(currency)
+	if ip == "" || len(fp) > d || strings.Contains(amount, ".") && fp == "" {
+		return Money{}, fmt.Errorf("query: invalid amount %q for %s", s, currency)
+	}
+	for len(fp) < d {
+		fp += "0"
+	}
+	for _, c := range ip + fp {
+		if c < '0' || c > '9' {
+			return Money{}, fmt.Errorf("query: invalid amount %q for %s", s, currency)
+		}
+	}
+
+	n, err := strconv.ParseInt(ip+fp, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("query: invalid amount %q for %s", s, currency)
+	}
+	if neg {
+		n = -n
+	}
+	return Money{Amount: n, Currency: currency}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMoney_encode(t *testing.T) {
+	s := struct {
+		Price  Money   `url:"price"`
+		Amount Money   `url:"amount,currency=currency"`
+		Fees   []Money `url:"fee"`
+		Tip    *Money  `url:"tip"`
+		None   *Money  `url:"none"`
+	}{
+		Tip:    &Money{100, "USD"},
+		Price:  Money{1999, "USD"},
+		Amount: Money{-50, "EUR"},
+		Fees:   []Money{{500, "JPY"}, {1234, "KWD"}},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"price":    {"19.99USD"},
+		"amount":   {"-0.50"},
+		"currency": {"EUR"},
+		"fee":      {"500JPY", "1.234KWD"},
+		"tip":      {"1.00USD"},
+		"none":     {""},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	if got, want := Keys(s), []string{"price", "amount", "currency", "fee", "tip", "none"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, got, want)
+	}
+
+	bad := struct{ M Money }{Money{1, "usd"}}
+	if _, err := Values(bad); err == nil {
+		t.Errorf("Values(%v) returned nil error for invalid currency", bad)
+	}
+
+	opt := struct {
+		Q     string `url:"q"`
+		Price Money  `url:"price,omitempty"`
+	}{Q: "x"}
+	v, err = Values(opt)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", opt, err)
+	}
+	if want := (url.Values{"q": {"x"}}); !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", opt, v, want)
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		s, currency string
+		want        Money
+		ok          bool
+	}{
+		{"19.99USD", "", Money{1999, "USD"}, true},
+		{"19.99", "USD", Money{1999, "USD"}, true},
+		{"19.9", "USD", Money{1990, "USD"}, true},
+		{"19", "USD", Money{1900, "USD"}, true},
+		{"-0.5", "EUR", Money{-50, "EUR"}, true},
+		{"500JPY", "", Money{500, "JPY"}, true},
+		{"1.234", "KWD", Money{1234, "KWD"}, true},
+		{"19.999", "USD", Money{}, false},
+		{"5.5", "JPY", Money{}, false},
+		{"19.", "USD", Money{}, false},
+		{".5", "USD", Money{}, false},
+		{"1e3", "USD", Money{}, false},
+		{"19.99", "usd", Money{}, false},
+		{"19.99", "", Money{}, false},
+		{"99999999999999999999", "USD", Money{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.s, tt.currency)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseMoney(%q, %q) returned %v, %v; want %v, ok=%v", tt.s, tt.currency, got, err, tt.want, tt.ok)
+		}
+	}
+
+	_, err := ParseMoney("19.99usd", "")
+	if want := `query: invalid currency "usd" in "19.99usd"`; err == nil || err.Error() != want {
+		t.Errorf("ParseMoney(%q, %q) returned error %v, want %q", "19.99usd", "", err, want)
+	}
+}
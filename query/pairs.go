@@ -0,0 +1,97 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A Pair is a single URL parameter name and value.
+type Pair struct {
+	Name, Value string
+}
+
+// Pairs returns the url.Values encoding of v, as returned by Values, as an
+// ordered list of name and value pairs.
+func Pairs(v interface{}) ([]Pair, error) {
+	return new(Config).Pairs(v)
+}
+
+// Pairs returns the url.Values encoding of v produced by c as an ordered list
+// of name and value pairs.  Parameters are in the order of their fields, as
+// listed by Keys, followed by any others, such as those produced by Encoder
+// and ExtraValuer implementations, sorted by name.  The values of each
+// parameter are in the order of url.Values.  Because Pairs is built on
+// Values, it supports the same options and hooks.
+func (c *Config) Pairs(v interface{}) ([]Pair, error) {
+	values, err := c.Values(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	if t := structType(v); t != nil {
+		c.rootKeys(t, func(key string, _ tagOptions, _ reflect.Type) {
+			for _, k := range matchingParams(values, key) {
+				if !seen[k] {
+					seen[k] = true
+					names = append(names, k)
+				}
+			}
+		})
+	}
+	var rest []string
+	for k := range values {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	var pairs []Pair
+	for _, k := range names {
+		for _, s := range values[k] {
+			pairs = append(pairs, Pair{k, s})
+		}
+	}
+	return pairs, nil
+}
+
+// Encode returns the encoding of v produced by c as a query string, without a
+// leading "?", with its parameters in the order of Pairs.  Unlike the Encode
+// method of url.Values, which sorts parameters by name, it keeps the order in
+// which they are declared.
+func (c *Config) Encode(v interface{}) (string, error) {
+	var b strings.Builder
+	if err := c.Write(&b, v); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Write writes the encoding of v produced by c to w as a query string, as
+// returned by Encode.
+func (c *Config) Write(w io.Writer, v interface{}) error {
+	pairs, err := c.Pairs(v)
+	if err != nil {
+		return err
+	}
+	for i, p := range pairs {
+		s := url.QueryEscape(p.Name) + "=" + url.QueryEscape(p.Value)
+		if i > 0 {
+			s = "&" + s
+		}
+		if _, err := io.WriteString(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPairs(t *testing.T) {
+	s := struct {
+		Z    string      `url:"z"`
+		IDs  []int       `url:"id,brackets"`
+		Arg  EncodedArgs `url:"arg"`
+		User struct {
+			Name string `url:"name"`
+		} `url:"user"`
+		A string `url:"a,omitempty"`
+		B bool   `url:"z"`
+	}{Z: "last", IDs: []int{2, 1}, Arg: EncodedArgs{"x"}, B: true}
+	s.User.Name = "a b"
+
+	want := []Pair{
+		{"z", "last"},
+		{"z", "true"},
+		{"id[]", "2"},
+		{"id[]", "1"},
+		{"user[name]", "a b"},
+		{"arg.0", "x"},
+	}
+	got, err := Pairs(s)
+	if err != nil {
+		t.Errorf("Pairs(%v) returned error: %v", s, err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Pairs(%v) returned %v, want %v", s, got, want)
+	}
+
+	c := &Config{Prefix: "x_"}
+	enc, err := c.Encode(s)
+	if err != nil {
+		t.Errorf("Encode(%v) returned error: %v", s, err)
+	}
+	if want := "x_z=last&x_z=true&x_id%5B%5D=2&x_id%5B%5D=1&x_user%5Bname%5D=a+b&x_arg.0=x"; enc != want {
+		t.Errorf("Encode(%v) returned %q, want %q", s, enc, want)
+	}
+
+	// maps have no declared order, so are sorted
+	got, err = Pairs(map[string]string{"b": "2", "a": "1"})
+	if err != nil {
+		t.Errorf("Pairs returned error: %v", err)
+	}
+	if want := []Pair{{"a", "1"}, {"b", "2"}}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Pairs returned %v, want %v", got, want)
+	}
+
+	if _, err := Pairs(1); err == nil {
+		t.Errorf("Pairs(1) returned nil error, want error")
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestConfig_Write(t *testing.T) {
+	s := struct {
+		B string `url:"b"`
+		A string `url:"a"`
+	}{"2", "1"}
+
+	var buf bytes.Buffer
+	if err := new(Config).Write(&buf, s); err != nil {
+		t.Errorf("Write(%v) returned error: %v", s, err)
+	}
+	if got, want := buf.String(), "b=2&a=1"; got != want {
+		t.Errorf("Write(%v) wrote %q, want %q", s, got, want)
+	}
+
+	if err := new(Config).Write(failWriter{}, s); err == nil {
+		t.Errorf("Write to failing writer returned nil error, want error")
+	}
+	if _, err := new(Config).Encode(1); err == nil {
+		t.Errorf("Encode(1) returned nil error, want error")
+	}
+}
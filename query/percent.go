@@ -0,0 +1,132 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var percentType = reflect.TypeOf(Percent(0))
+
+// A Percent is a percentage, measured in percentage points: Percent(15) is
+// 15%, or a fraction of 0.15.
+//
+// Percent values default to encoding as the number of percentage points,
+// such as "15".  Including the "fraction" option encodes the value as a
+// fraction, such as "0.15", including the "bp" option encodes it in basis
+// points, such as "1500", and including the "sign" option encodes it with a
+// percent sign, such as "15%".  Values are scaled by moving the decimal
+// point, so no rounding error is introduced.  NaN and infinite values are
+// encoded unchanged, as "NaN", "+Inf" or "-Inf", whatever the options.
+type Percent float64
+
+// Fraction returns p as a fraction, such as 0.15 for 15%.  NaN and infinite
+// values are returned unchanged.
+func (p Percent) Fraction() float64 {
+	if !p.finite() {
+		return float64(p)
+	}
+	f, _ := strconv.ParseFloat(shiftDecimal(p.points(), -2), 64)
+	return f
+}
+
+// finite reports whether p is neither NaN nor infinite.
+func (p Percent) finite() bool {
+	return !math.IsNaN(float64(p)) && !math.IsInf(float64(p), 0)
+}
+
+// String returns p in percentage points, followed by a percent sign.
+func (p Percent) String() string {
+	return p.points() + "%"
+}
+
+// points returns p in percentage points, as a plain decimal number.
+func (p Percent) points() string {
+	return strconv.FormatFloat(float64(p), 'f', -1, 64)
+}
+
+// format returns p as specified by the options described for Percent.
+func (p Percent) format(opts tagOptions) string {
+	s := p.points()
+	if !p.finite() {
+		return s
+	}
+	switch {
+	case opts.Contains("fraction"):
+		return shiftDecimal(s, -2)
+	case opts.Contains("bp"):
+		return shiftDecimal(s, 2)
+	case opts.Contains("sign"):
+		return s + "%"
+	}
+	return s
+}
+
+// ParsePercent parses s as a percentage.  A trailing "%" marks s as being in
+// percentage points, and a trailing "bp" as being in basis points.  Otherwise,
+// s is interpreted as a fraction if fraction is true, or in percentage points
+// if not.  Thus "15", "15%", "1500bp" and, with fraction true, "0.15" all
+// parse as Percent(15).
+func ParsePercent(s string, fraction bool) (Percent, error) {
+	num, shift := s, 0
+	switch {
+	case strings.HasSuffix(s, "%"):
+		num = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	case strings.HasSuffix(s, "bp"):
+		num, shift = strings.TrimSpace(strings.TrimSuffix(s, "bp")), -2
+	case fraction:
+		shift = 2
+	}
+
+	if _, err := strconv.ParseFloat(num, 64); err != nil || strings.ContainsAny(num, "eEnNiIxX_") {
+		return 0, fmt.Errorf("query: invalid percentage %q", s)
+	}
+	f, err := strconv.ParseFloat(shiftDecimal(num, shift), 64)
+	if err != nil {
+		return 0, fmt.Errorf("query: invalid percentage %q", s)
+	}
+	return Percent(f), nil
+}
+
+// shiftDecimal multiplies the plain decimal number s by 10**n by moving its
+// decimal point, returning the result without redundant zeros.
+func shiftDecimal(s string, n int) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+
+	digits, point := s, len(s)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits, point = s[:i]+s[i+1:], i
+	}
+	point += n
+	for point <= 0 {
+		digits = "0" + digits
+		point++
+	}
+	for point > len(digits) {
+		digits += "0"
+	}
+
+	ip, fp := strings.TrimLeft(digits[:point], "0"), strings.TrimRight(digits[point:], "0")
+	if ip == "" {
+		ip = "0"
+	}
+	if ip == "0" && fp == "" {
+		return "0"
+	}
+	if fp == "" {
+		return sign + ip
+	}
+	return sign + ip + "." + fp
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestPercent_encode(t *testing.T) {
+	s := struct {
+		A Percent
+		B Percent   `url:",fraction"`
+		C Percent   `url:",bp"`
+		D Percent   `url:",sign"`
+		E []Percent `url:",fraction,comma"`
+	}{12.5, 12.5, 12.34, 7, []Percent{0.5, -3, 100}}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"A": {"12.5"},
+		"B": {"0.125"},
+		"C": {"1234"},
+		"D": {"7%"},
+		"E": {"0.005,-0.03,1"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	inf := struct {
+		A Percent   `url:",fraction"`
+		B Percent   `url:",bp"`
+		C []Percent `url:",sign,comma"`
+	}{Percent(math.Inf(1)), Percent(math.NaN()), []Percent{Percent(math.Inf(-1))}}
+	v, err = Values(inf)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", inf, err)
+	}
+	want = url.Values{"A": {"+Inf"}, "B": {"NaN"}, "C": {"-Inf"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", inf, v, want)
+	}
+
+	if f := Percent(math.NaN()).Fraction(); !math.IsNaN(f) {
+		t.Errorf("Percent(NaN).Fraction() returned %v, want NaN", f)
+	}
+	if f := Percent(math.Inf(-1)).Fraction(); !math.IsInf(f, -1) {
+		t.Errorf("Percent(-Inf).Fraction() returned %v, want -Inf", f)
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in       string
+		fraction bool
+		want     Percent
+		ok       bool
+	}{
+		{"15", false, 15, true},
+		{"15%", false, 15, true},
+		{"15 %", true, 15, true},
+		{"1500bp", false, 15, true},
+		{"0.15", true, 15, true},
+		{"0.15", false, 0.15, true},
+		{"-2.5", true, -250, true},
+		{"1e2", false, 0, false},
+		{"abc%", false, 0, false},
+		{"", false, 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParsePercent(tt.in, tt.fraction)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParsePercent(%q, %v) returned %v, %v; want %v, ok=%v", tt.in, tt.fraction, got, err, tt.want, tt.ok)
+		}
+	}
+
+	if got := Percent(15).Fraction(); got != 0.15 {
+		t.Errorf("Percent(15).Fraction() returned %v, want 0.15", got)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// A Range is an interval of values, such as a price or date range, with
+// optional lower and upper bounds.  A nil bound leaves that end of the range
+// open.
+//
+// Range values default to encoding as a single packed value joining the bounds
+// with "..", such as "price=10..50", "price=10.." or "price=..50".  Including
+// the "split" option instead encodes each bound as a separate URL parameter,
+// named by adding "_min" and "_max" to the field's name, such as
+// "price_min=10&price_max=50".  Bounds are formatted like other field values,
+// using the field's options.  A Range with neither bound is omitted, and
+// encoding fails if Min is greater than Max.
+type Range[T any] struct {
+	Min *T
+	Max *T
+}
+
+// NewRange returns a Range with both bounds set.
+func NewRange[T any](min, max T) Range[T] {
+	return Range[T]{Min: &min, Max: &max}
+}
+
+func (r Range[T]) encodeValues(c *Config, values url.Values, name string, opts tagOptions) error {
+	if r.Min != nil && r.Max != nil && less(reflect.ValueOf(*r.Max), reflect.ValueOf(*r.Min)) {
+		return fmt.Errorf("query: range %s has minimum %v greater than maximum %v", name, *r.Min, *r.Max)
+	}
+
+	var min, max string
+	if r.Min != nil {
+		min = c.valueString(reflect.ValueOf(*r.Min), opts)
+	}
+	if r.Max != nil {
+		max = c.valueString(reflect.ValueOf(*r.Max), opts)
+	}
+
+	if opts.Contains("split") {
+		if r.Min != nil {
+			values.Add(name+"_min", min)
+		}
+		if r.Max != nil {
+			values.Add(name+"_max", max)
+		}
+		return nil
+	}
+	if r.Min != nil || r.Max != nil {
+		values.Add(name, min+".."+max)
+	}
+	return nil
+}
+
+func (r Range[T]) keys(name string, opts tagOptions) []string {
+	if opts.Contains("split") {
+		return []string{name + "_min", name + "_max"}
+	}
+	return []string{name}
+}
+
+// less reports whether a is ordered before b.  Numbers, strings and
+// time.Time values are ordered; values of other types never are.
+func less(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.String:
+		return a.String() < b.String()
+	}
+	if a.Type() == timeType {
+		return a.Interface().(time.Time).Before(b.Interface().(time.Time))
+	}
+	return false
+}
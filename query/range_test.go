@@ -0,0 +1,56 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRange_encode(t *testing.T) {
+	ten := 10
+	s := struct {
+		Price   Range[int]       `url:"price"`
+		Weight  Range[float64]   `url:"weight,split"`
+		Size    Range[int]       `url:"size"`
+		Created Range[time.Time] `url:"created,unix,split"`
+		Name    Range[string]    `url:"name"`
+		None    Range[int]       `url:"none"`
+	}{
+		Price:   NewRange(10, 50),
+		Weight:  Range[float64]{Max: new(float64)},
+		Size:    Range[int]{Min: &ten},
+		Created: NewRange(time.Unix(1000, 0), time.Unix(2000, 0)),
+		Name:    NewRange("a", "m"),
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"price":       {"10..50"},
+		"weight_max":  {"0"},
+		"size":        {"10.."},
+		"created_min": {"1000"},
+		"created_max": {"2000"},
+		"name":        {"a..m"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	wantKeys := []string{"price", "weight_min", "weight_max", "size", "created_min", "created_max", "name", "none"}
+	if got := Keys(s); !reflect.DeepEqual(wantKeys, got) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, got, wantKeys)
+	}
+
+	bad := struct{ R Range[int] }{NewRange(5, 1)}
+	if _, err := Values(bad); err == nil {
+		t.Errorf("Values(%v) returned nil error for inverted range", bad)
+	}
+}
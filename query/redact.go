@@ -0,0 +1,50 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// Redacted is the placeholder value used in place of fields marked with the
+// "secret" option when redacting.
+const Redacted = "REDACTED"
+
+// Redact returns the url.Values encoding of v, as Values does, except that
+// each parameter produced by a field with the "secret" option is encoded as a
+// single Redacted value.
+// The result is suitable for logging.
+func Redact(v interface{}) (url.Values, error) {
+	return (&Config{Redact: true}).Values(v)
+}
+
+// RedactValues returns a copy of values in which each value of a parameter
+// produced by a "secret" field of prototype is replaced by Redacted.  All other
+// parameters are copied unchanged.
+func RedactValues(values url.Values, prototype interface{}) url.Values {
+	secret := make(map[string]bool)
+	if t := structType(prototype); t != nil {
+		new(Config).typeKeys(t, "", func(key string, opts tagOptions, _ reflect.Type) {
+			if opts.Contains("secret") {
+				for _, k := range matchingParams(values, key) {
+					secret[k] = true
+				}
+			}
+		}, map[reflect.Type]bool{})
+	}
+
+	redacted := make(url.Values)
+	for key, vs := range values {
+		vs = append([]string(nil), vs...)
+		if secret[key] {
+			for i := range vs {
+				vs[i] = Redacted
+			}
+		}
+		redacted[key] = vs
+	}
+	return redacted
+}
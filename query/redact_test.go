@@ -0,0 +1,165 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type credentials struct {
+	User  string `url:"user"`
+	Token string `url:"token,secret"`
+}
+
+type login struct {
+	Creds  credentials `url:"creds,secret"`
+	Email  string      `url:"email,secret,omitempty"`
+	Scopes []string    `url:"scope"`
+}
+
+func TestRedact(t *testing.T) {
+	s := login{
+		Creds:  credentials{User: "u", Token: "t"},
+		Scopes: []string{"a", "b"},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"creds[user]":  {"u"},
+		"creds[token]": {"t"},
+		"scope":        {"a", "b"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	v, err = Redact(s)
+	if err != nil {
+		t.Errorf("Redact(%v) returned error: %v", s, err)
+	}
+	want = url.Values{
+		"creds[user]":  {Redacted},
+		"creds[token]": {Redacted},
+		"scope":        {"a", "b"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Redact(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+func TestRedactValues(t *testing.T) {
+	values := url.Values{
+		"creds[user]":  {"u"},
+		"creds[token]": {"t"},
+		"email":        {"a@example.com", "b@example.com"},
+		"scope":        {"a"},
+		"other":        {"x"},
+	}
+
+	got := RedactValues(values, &login{})
+	want := url.Values{
+		"creds[user]":  {Redacted},
+		"creds[token]": {Redacted},
+		"email":        {Redacted, Redacted},
+		"scope":        {"a"},
+		"other":        {"x"},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("RedactValues(%v) returned %v, want %v", values, got, want)
+	}
+	if values.Get("email") != "a@example.com" {
+		t.Errorf("RedactValues modified its input values")
+	}
+}
+
+func TestRedact_composite(t *testing.T) {
+	s := struct {
+		Cred [2]string `url:"user+pass,secret"`
+		Q    string    `url:"q"`
+	}{[2]string{"bob", "hunter2"}, "x"}
+
+	v, err := Redact(s)
+	if err != nil {
+		t.Errorf("Redact(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"user": {Redacted},
+		"pass": {Redacted},
+		"q":    {"x"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Redact(%v) returned %v, want %v", s, v, want)
+	}
+
+	values, _ := Values(s)
+	if got := RedactValues(values, s); !reflect.DeepEqual(want, got) {
+		t.Errorf("RedactValues(%v) returned %v, want %v", values, got, want)
+	}
+}
+
+func TestRedact_group(t *testing.T) {
+	type card struct {
+		Number string `url:"card"`
+	}
+	s := struct {
+		Items []card `url:",group,secret"`
+		Q     string `url:"q"`
+	}{[]card{{"4111"}, {"5500"}}, "x"}
+
+	v, err := Redact(s)
+	if err != nil {
+		t.Errorf("Redact(%v) returned error: %v", s, err)
+	}
+	want := url.Values{"card": {Redacted}, "q": {"x"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Redact(%v) returned %v, want %v", s, v, want)
+	}
+
+	values, _ := Values(s)
+	got := RedactValues(values, s)
+	want = url.Values{"card": {Redacted, Redacted}, "q": {"x"}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("RedactValues(%v) returned %v, want %v", values, got, want)
+	}
+}
+
+func TestRedact_matchesRedactValues(t *testing.T) {
+	s := struct {
+		Extra  map[string]string `url:"extra,secret"`
+		U      credentials       `url:"u,secret"`
+		P      *credentials      `url:"p,secret"`
+		IDs    []int             `url:"ids,brackets,secret"`
+		Public string            `url:"public"`
+	}{
+		Extra:  map[string]string{"a": "1", "b": "2"},
+		U:      credentials{User: "u", Token: "t"},
+		IDs:    []int{1, 2},
+		Public: "x",
+	}
+
+	got, err := Redact(s)
+	if err != nil {
+		t.Errorf("Redact(%v) returned error: %v", s, err)
+	}
+	values, _ := Values(s)
+	want := RedactValues(values, s)
+	for k, vs := range want {
+		// Redact encodes each parameter as a single placeholder
+		if vs[0] == Redacted {
+			want[k] = vs[:1]
+		}
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Redact(%v) returned %v, want %v", s, got, want)
+	}
+	if got.Get("extra[a]") != Redacted || got.Get("u[token]") != Redacted || got.Get("p") != Redacted {
+		t.Errorf("Redact(%v) returned %v, want map and struct parameters redacted", s, got)
+	}
+}
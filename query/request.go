@@ -0,0 +1,85 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxURLLength is the URL length used by a RequestBuilder whose
+// MaxLength is zero.  Many browsers, proxies and servers reject longer URLs.
+const DefaultMaxURLLength = 2000
+
+// A URLTooLongError is returned by RequestBuilder.NewRequest when the encoded
+// URL would be longer than its limit and no fallback is enabled.
+type URLTooLongError struct {
+	Length int // length of the encoded URL
+	Max    int // maximum length allowed
+}
+
+func (e *URLTooLongError) Error() string {
+	return fmt.Sprintf("query: URL length %d exceeds maximum of %d", e.Length, e.Max)
+}
+
+// A RequestBuilder builds HTTP requests that carry the url.Values encoding of
+// a struct, keeping URLs within a length limit.
+//
+// To shorten URLs rather than fall back to POST, set a Config.HashStore and
+// tag the longest fields with the "hash" option.
+type RequestBuilder struct {
+	// Config, if non-nil, is used to encode values.
+	Config *Config
+
+	// MaxLength is the maximum length of a URL, in bytes.  If zero,
+	// DefaultMaxURLLength is used.
+	MaxLength int
+
+	// PostFallback causes a request whose URL would be too long to be sent
+	// as a POST with the values in an "application/x-www-form-urlencoded"
+	// body instead.  Otherwise, NewRequest returns a *URLTooLongError.
+	PostFallback bool
+}
+
+// NewRequest returns a GET request for rawurl with its query replaced by the
+// encoding of v, or a POST of the encoding of v to rawurl if that URL would be
+// too long and b.PostFallback is set.
+func (b *RequestBuilder) NewRequest(ctx context.Context, rawurl string, v interface{}) (*http.Request, error) {
+	c := b.Config
+	if c == nil {
+		c = new(Config)
+	}
+	values, err := c.Values(v)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	encoded := values.Encode()
+	u.RawQuery = encoded
+
+	max := b.MaxLength
+	if max == 0 {
+		max = DefaultMaxURLLength
+	}
+	if s := u.String(); len(s) <= max {
+		return http.NewRequestWithContext(ctx, http.MethodGet, s, nil)
+	} else if !b.PostFallback {
+		return nil, &URLTooLongError{Length: len(s), Max: max}
+	}
+
+	u.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", FormatForm.ContentType())
+	return req, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRequestBuilder(t *testing.T) {
+	s := struct {
+		IDs []string `url:"id,comma"`
+	}{[]string{strings.Repeat("a", 20), strings.Repeat("b", 20)}}
+	const base = "https://example.com/items?old=1"
+	const query = "id=aaaaaaaaaaaaaaaaaaaa%2Cbbbbbbbbbbbbbbbbbbbb"
+
+	// fits within the limit
+	b := &RequestBuilder{MaxLength: 100}
+	req, err := b.NewRequest(context.Background(), base, s)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	if req.Method != "GET" || req.URL.String() != "https://example.com/items?"+query {
+		t.Errorf("NewRequest returned %s %s", req.Method, req.URL)
+	}
+
+	// too long, without fallback
+	b.MaxLength = 50
+	_, err = b.NewRequest(context.Background(), base, s)
+	var tooLong *URLTooLongError
+	if !errors.As(err, &tooLong) || tooLong.Max != 50 || tooLong.Length != 72 {
+		t.Errorf("NewRequest returned error %v, want URLTooLongError of length 72", err)
+	}
+
+	// too long, with fallback
+	b.PostFallback = true
+	req, err = b.NewRequest(context.Background(), base, s)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	if req.Method != "POST" || req.URL.String() != "https://example.com/items" {
+		t.Errorf("NewRequest returned %s %s", req.Method, req.URL)
+	}
+	if got, want := req.Header.Get("Content-Type"), "application/x-www-form-urlencoded"; got != want {
+		t.Errorf("NewRequest returned Content-Type %q, want %q", got, want)
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != query {
+		t.Errorf("NewRequest returned body %q, want %q", body, query)
+	}
+
+	// the Config is used to encode
+	b = &RequestBuilder{Config: &Config{Prefix: "x_"}}
+	req, err = b.NewRequest(context.Background(), "/items", s)
+	if err != nil {
+		t.Fatalf("NewRequest returned error: %v", err)
+	}
+	if got, want := req.URL.String(), "/items?x_"+query; got != want {
+		t.Errorf("NewRequest returned URL %q, want %q", got, want)
+	}
+
+	if _, err := b.NewRequest(context.Background(), "%zz", s); err == nil {
+		t.Errorf("NewRequest with invalid URL returned nil error, want error")
+	}
+	if _, err := b.NewRequest(context.Background(), "/items", 1); err == nil {
+		t.Errorf("NewRequest with int input returned nil error, want error")
+	}
+}
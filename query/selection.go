@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"strings"
+)
+
+// A Selection is a list of items to include and items to exclude, such as
+// the fields to return from an API.  It is encoded as a single comma-separated
+// value, with a leading minus sign marking each excluded item, e.g:
+//
+// 	"fields=id,name,-email"
+//
+// An empty Selection is omitted.
+type Selection struct {
+	Include []string
+	Exclude []string
+}
+
+// ParseSelection parses a comma-separated list of items, in which a leading
+// minus sign marks an item to exclude.  Empty items are ignored.
+func ParseSelection(s string) Selection {
+	var sel Selection
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.HasPrefix(item, "-"):
+			if item = item[1:]; item != "" {
+				sel.Exclude = append(sel.Exclude, item)
+			}
+		case item != "":
+			sel.Include = append(sel.Include, item)
+		}
+	}
+	return sel
+}
+
+// String returns s in its encoded form.
+func (s Selection) String() string {
+	items := append([]string(nil), s.Include...)
+	for _, item := range s.Exclude {
+		items = append(items, "-"+item)
+	}
+	return strings.Join(items, ",")
+}
+
+// EncodeValues implements the Encoder interface.
+func (s Selection) EncodeValues(key string, v *url.Values) error {
+	if len(s.Include) > 0 || len(s.Exclude) > 0 {
+		v.Add(key, s.String())
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Selection
+	}{
+		{"a,b,-c", Selection{Include: []string{"a", "b"}, Exclude: []string{"c"}}},
+		{" -a , b,,-", Selection{Include: []string{"b"}, Exclude: []string{"a"}}},
+		{"", Selection{}},
+	}
+	for _, tt := range tests {
+		if got := ParseSelection(tt.in); !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("ParseSelection(%q) returned %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSelection_encode(t *testing.T) {
+	s := struct {
+		Fields Selection `url:"fields"`
+		Embed  Selection `url:"embed"`
+	}{
+		Fields: Selection{Include: []string{"id", "name"}, Exclude: []string{"email"}},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{"fields": {"id,name,-email"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
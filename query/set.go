@@ -0,0 +1,98 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// A Set is a collection of distinct values, such as the values of a filter
+// parameter.
+//
+// Set values are encoded like slices of their elements, including the
+// "comma", "space", "pipe" and "brackets" options, with the elements in
+// sorted order so that the encoding is deterministic.  Numbers, strings and
+// time.Time values are sorted by value, and other types by their encoded
+// strings.
+type Set[T comparable] map[T]struct{}
+
+// NewSet returns a Set containing elems.
+func NewSet[T comparable](elems ...T) Set[T] {
+	s := make(Set[T], len(elems))
+	s.Add(elems...)
+	return s
+}
+
+// Add adds elems to s.
+func (s Set[T]) Add(elems ...T) {
+	for _, e := range elems {
+		s[e] = struct{}{}
+	}
+}
+
+// Remove removes elems from s.
+func (s Set[T]) Remove(elems ...T) {
+	for _, e := range elems {
+		delete(s, e)
+	}
+}
+
+// Contains reports whether e is in s.
+func (s Set[T]) Contains(e T) bool {
+	_, ok := s[e]
+	return ok
+}
+
+// Len returns the number of elements in s.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Sorted returns the elements of s in the order in which they are encoded.
+func (s Set[T]) Sorted() []T {
+	elems := make([]T, 0, len(s))
+	for e := range s {
+		elems = append(elems, e)
+	}
+	sort.Slice(elems, func(i, j int) bool {
+		a, b := reflect.ValueOf(elems[i]), reflect.ValueOf(elems[j])
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return fmt.Sprint(elems[i]) < fmt.Sprint(elems[j])
+	})
+	return elems
+}
+
+func (s Set[T]) encodeValues(c *Config, values url.Values, name string, opts tagOptions) error {
+	var strs []string
+	for _, e := range s.Sorted() {
+		strs = append(strs, c.valueString(reflect.ValueOf(e), opts))
+	}
+
+	if del := opts.delimiter(); del != 0 {
+		values.Add(name, strings.Join(strs, string(del)))
+		return nil
+	}
+	name = s.keys(name, opts)[0]
+	for _, str := range strs {
+		values.Add(name, str)
+	}
+	return nil
+}
+
+func (s Set[T]) keys(name string, opts tagOptions) []string {
+	if opts.delimiter() == 0 && opts.Contains("brackets") {
+		name += "[]"
+	}
+	return []string{name}
+}
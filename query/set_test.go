@@ -0,0 +1,59 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet("b", "a", "b")
+	if s.Len() != 2 || !s.Contains("a") || s.Contains("c") {
+		t.Errorf("NewSet(b, a, b) = %v, want {a, b}", s)
+	}
+	s.Add("c")
+	s.Remove("a")
+	if got, want := s.Sorted(), []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sorted() returned %q, want %q", got, want)
+	}
+}
+
+func TestSet_encode(t *testing.T) {
+	s := struct {
+		A Set[string]
+		B Set[int]  `url:",comma"`
+		C Set[bool] `url:",int,space"`
+		D Set[int]  `url:",brackets"`
+		E Set[int]  `url:",omitempty"`
+		F *Set[int]
+	}{
+		A: NewSet("z", "a", "m"),
+		B: NewSet(10, 9, 100),
+		C: NewSet(true, false),
+		D: NewSet(2, 1),
+		E: NewSet[int](),
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"A":   {"a", "m", "z"},
+		"B":   {"9,10,100"},
+		"C":   {"0 1"},
+		"D[]": {"1", "2"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	wantKeys := []string{"A", "B", "C", "D[]", "E", "F"}
+	if got := Keys(s); !reflect.DeepEqual(wantKeys, got) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, got, wantKeys)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/url"
+)
+
+// ErrInvalidSignature is returned by VerifySignature when the signature
+// parameter is missing or does not match.
+var ErrInvalidSignature = errors.New("query: invalid signature")
+
+// Signature returns the hex-encoded HMAC, using hash function h and key, of
+// the canonical string of values (see SigV4Query).
+func Signature(values url.Values, h func() hash.Hash, key []byte) string {
+	mac := hmac.New(h, key)
+	mac.Write([]byte(canonicalString(values)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks that the single value of the param parameter of
+// values is the Signature of the remaining parameters, using hash function h
+// and key.  It is intended for callbacks that deliver events entirely as
+// query parameters, and returns ErrInvalidSignature if the signature is
+// missing, repeated or incorrect.
+func VerifySignature(values url.Values, param string, h func() hash.Hash, key []byte) error {
+	sig := values[param]
+	if len(sig) != 1 {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(sig[0])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	rest := make(url.Values, len(values))
+	for k, vs := range values {
+		if k != param {
+			rest[k] = vs
+		}
+	}
+	want, _ := hex.DecodeString(Signature(rest, h, key))
+	if !hmac.Equal(got, want) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"crypto/sha256"
+	"net/url"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	key := []byte("secret")
+	values := url.Values{
+		"event": {"paid"},
+		"id":    {"42"},
+	}
+	signed := url.Values{
+		"event": {"paid"},
+		"id":    {"42"},
+		"sig":   {Signature(values, sha256.New, key)},
+	}
+	if err := VerifySignature(signed, "sig", sha256.New, key); err != nil {
+		t.Errorf("VerifySignature(%v) returned error: %v", signed, err)
+	}
+
+	tests := []url.Values{
+		{"event": {"paid"}, "id": {"43"}, "sig": signed["sig"]},
+		{"event": {"paid"}, "id": {"42"}},
+		{"event": {"paid"}, "id": {"42"}, "sig": {"zz"}},
+		{"event": {"paid"}, "id": {"42"}, "sig": {signed.Get("sig"), signed.Get("sig")}},
+	}
+	for i, tt := range tests {
+		if err := VerifySignature(tt, "sig", sha256.New, key); err != ErrInvalidSignature {
+			t.Errorf("%d. VerifySignature(%v) returned %v, want ErrInvalidSignature", i, tt, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"strings"
+)
+
+// TrackingParams lists the names of common analytics and ad click tracking
+// parameters.  A name ending in "*" matches any name with that prefix.
+var TrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"gclsrc",
+	"dclid",
+	"msclkid",
+	"yclid",
+	"twclid",
+	"igshid",
+	"mc_cid",
+	"mc_eid",
+	"_ga",
+	"_gl",
+}
+
+// A Stripper removes unwanted parameters, such as tracking parameters, from
+// url.Values or URLs.  The zero Stripper removes TrackingParams.
+type Stripper struct {
+	// Params lists the names of the parameters to remove.  A name ending in
+	// "*" matches any name with that prefix.  If nil, TrackingParams is used.
+	Params []string
+}
+
+// StripTracking returns a copy of values without any TrackingParams.
+func StripTracking(values url.Values) url.Values {
+	return new(Stripper).Values(values)
+}
+
+// Match reports whether the parameter name is one that s removes.
+func (s *Stripper) Match(name string) bool {
+	params := s.Params
+	if params == nil {
+		params = TrackingParams
+	}
+	for _, p := range params {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(name, p[:len(p)-1]) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Values returns a copy of values without the parameters that s removes.
+func (s *Stripper) Values(values url.Values) url.Values {
+	stripped := make(url.Values)
+	for k, vs := range values {
+		if !s.Match(k) {
+			stripped[k] = append([]string(nil), vs...)
+		}
+	}
+	return stripped
+}
+
+// URL returns rawurl without the query parameters that s removes.  The
+// remaining parameters are left in their original order and encoding, and
+// the "?" is dropped if none remain.
+func (s *Stripper) URL(rawurl string) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	if u.RawQuery == "" {
+		return rawurl, nil
+	}
+
+	var kept []string
+	for _, p := range strings.Split(u.RawQuery, "&") {
+		name := p
+		if i := strings.IndexByte(p, '='); i >= 0 {
+			name = p[:i]
+		}
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if !s.Match(name) {
+			kept = append(kept, p)
+		}
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	u.ForceQuery = false
+	return u.String(), nil
+}
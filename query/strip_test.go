@@ -0,0 +1,56 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestStripTracking(t *testing.T) {
+	values := url.Values{
+		"q":            {"go"},
+		"utm_source":   {"mail"},
+		"utm_campaign": {"fall"},
+		"fbclid":       {"x"},
+		"gclid_extra":  {"kept"},
+	}
+	want := url.Values{"q": {"go"}, "gclid_extra": {"kept"}}
+	if got := StripTracking(values); !reflect.DeepEqual(got, want) {
+		t.Errorf("StripTracking(%v) returned %v, want %v", values, got, want)
+	}
+	if len(values) != 5 {
+		t.Errorf("StripTracking modified its input: %v", values)
+	}
+}
+
+func TestStripper_URL(t *testing.T) {
+	s := &Stripper{Params: []string{"ref", "x_*"}}
+	tests := []struct {
+		s    *Stripper
+		in   string
+		want string
+	}{
+		{new(Stripper), "https://a.com/p?utm_source=x&b=2&a=1#top", "https://a.com/p?b=2&a=1#top"},
+		{new(Stripper), "https://a.com/p?utm_source=x", "https://a.com/p"},
+		{new(Stripper), "/p?utm%5Fmedium=x&q=a%20b", "/p?q=a%20b"},
+		{new(Stripper), "/p", "/p"},
+		{s, "/p?ref=1&x_a=2&utm_source=3", "/p?utm_source=3"},
+	}
+	for _, tt := range tests {
+		got, err := tt.s.URL(tt.in)
+		if err != nil {
+			t.Errorf("URL(%q) returned error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("URL(%q) returned %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := new(Stripper).URL("%zz"); err == nil {
+		t.Errorf("URL with invalid URL returned nil error, want error")
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// TagInfo is the parsed form of a struct field's url tag, as interpreted by
+// Values.
+type TagInfo struct {
+	// Name is the URL parameter name of the field: the name given in its
+	// tag, or else the field's own name.  It is empty for an embedded struct
+	// whose fields are promoted.
+	Name string
+
+	// Options lists the comma-separated options following the name in the
+	// tag, such as "omitempty".
+	Options []string
+
+	// Skip reports whether the field is not encoded at all, because it is
+	// unexported or its tag is "-".
+	Skip bool
+
+	// Embedded reports whether the field is an anonymous struct, or pointer
+	// to a struct, without a tag name, whose fields are encoded as if they
+	// were fields of the outer struct.
+	Embedded bool
+}
+
+// Contains reports whether the option is present in t.
+func (t TagInfo) Contains(option string) bool {
+	return tagOptions(t.Options).Contains(option)
+}
+
+// Value returns the value of an option of the form "option=value" in t, and
+// whether it was present.
+func (t TagInfo) Value(option string) (string, bool) {
+	return tagOptions(t.Options).Value(option)
+}
+
+// ParseFieldTag parses the url tag of sf, in the same way as Values.  It
+// returns the parsed tag along with an error if the tag is well-formed but
+// has no effect or conflicting effects, such as a tag on an unexported
+// field, an empty option, more than one of "comma", "space" and "pipe", both
+// "ro" and "wo", or a "prec" option that is not a non-negative integer.  It
+// is intended for tooling and custom encoders that reason about tags.
+func ParseFieldTag(sf reflect.StructField) (TagInfo, error) {
+	tag, tagged := sf.Tag.Lookup("url")
+	if sf.PkgPath != "" {
+		if tagged && tag != "-" {
+			return TagInfo{Skip: true}, fmt.Errorf("query: field %s has url tag %q but is unexported", sf.Name, tag)
+		}
+		return TagInfo{Skip: true}, nil
+	}
+	if tag == "-" {
+		return TagInfo{Skip: true}, nil
+	}
+
+	name, opts := parseTag(tag)
+	t := TagInfo{Name: name, Options: opts}
+	if name == "" {
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct {
+			t.Embedded = true
+		} else {
+			t.Name = sf.Name
+		}
+	}
+
+	delims := 0
+	for _, o := range opts {
+		switch o {
+		case "":
+			return t, fmt.Errorf("query: field %s has empty option in url tag %q", sf.Name, tag)
+		case "comma", "space", "pipe":
+			delims++
+		}
+	}
+	if t.Contains("ro") && t.Contains("wo") {
+		return t, fmt.Errorf("query: field %s has both ro and wo options in url tag %q", sf.Name, tag)
+	}
+	if delims > 1 {
+		return t, fmt.Errorf("query: field %s has conflicting delimiter options in url tag %q", sf.Name, tag)
+	}
+	if p, ok := t.Value("prec"); ok {
+		if n, err := strconv.Atoi(p); err != nil || n < 0 {
+			return t, fmt.Errorf("query: field %s has invalid prec option in url tag %q", sf.Name, tag)
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldTag(t *testing.T) {
+	type Inner struct{}
+	typ := reflect.TypeOf(struct {
+		A string `url:"a,omitempty,prec=2"`
+		B string
+		C string `url:"-"`
+		d string `url:"d"`
+		e string
+		Inner
+		*Nested `url:",omitempty"`
+		F       Inner `url:"f"`
+		G       []int `url:"g,comma,pipe"`
+		H       int   `url:"h,,int"`
+		I       int   `url:"i,prec=x"`
+		J       int   `url:"j,ro,wo"`
+	}{})
+
+	tests := []struct {
+		field   string
+		want    TagInfo
+		wantErr bool
+	}{
+		{"A", TagInfo{Name: "a", Options: []string{"omitempty", "prec=2"}}, false},
+		{"B", TagInfo{Name: "B", Options: []string{}}, false},
+		{"C", TagInfo{Skip: true}, false},
+		{"d", TagInfo{Skip: true}, true},
+		{"e", TagInfo{Skip: true}, false},
+		{"Inner", TagInfo{Options: []string{}, Embedded: true}, false},
+		{"Nested", TagInfo{Options: []string{"omitempty"}, Embedded: true}, false},
+		{"F", TagInfo{Name: "f", Options: []string{}}, false},
+		{"G", TagInfo{Name: "g", Options: []string{"comma", "pipe"}}, true},
+		{"H", TagInfo{Name: "h", Options: []string{"", "int"}}, true},
+		{"I", TagInfo{Name: "i", Options: []string{"prec=x"}}, true},
+		{"J", TagInfo{Name: "j", Options: []string{"ro", "wo"}}, true},
+	}
+	for _, tt := range tests {
+		sf, _ := typ.FieldByName(tt.field)
+		got, err := ParseFieldTag(sf)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFieldTag(%s) returned error %v, want error %v", tt.field, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("ParseFieldTag(%s) returned %+v, want %+v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestTagInfo(t *testing.T) {
+	info := TagInfo{Name: "a", Options: []string{"omitempty", "prec=2"}}
+	if !info.Contains("omitempty") || info.Contains("prec") {
+		t.Errorf("Contains returned wrong results for %+v", info)
+	}
+	if v, ok := info.Value("prec"); !ok || v != "2" {
+		t.Errorf("Value(prec) returned %q, %v, want %q, true", v, ok, "2")
+	}
+}
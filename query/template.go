@@ -0,0 +1,101 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Expand renders the URL template tmpl by replacing each placeholder, a URL
+// parameter name in braces such as "{page}" or "{user[name]}", with the value
+// of that parameter in the url.Values encoding of v.  e.g:
+//
+// 	// u is "/users/42/search?q=go+lang&page=2"
+// 	u, err := query.Expand("/users/{id}/search?q={q}&page={page}", opts)
+//
+// Values are escaped according to their position in the template: as a path
+// segment before any "?", and as a query component after it, so a value
+// containing "/" or "&" cannot change the structure of the URL.  A parameter
+// with multiple values is expanded to its escaped values separated by commas,
+// and a parameter that v omits, such as an empty "omitempty" field, is
+// expanded to the empty string.
+//
+// Expand returns an error if a placeholder is unterminated, or if v is a
+// struct and a placeholder names a parameter that its type cannot produce
+// (see Keys).
+func Expand(tmpl string, v interface{}) (string, error) {
+	return new(Config).Expand(tmpl, v)
+}
+
+// Expand renders the URL template tmpl using the url.Values encoding of v
+// produced by c, as described in the documentation for the package-level
+// Expand function.
+func (c *Config) Expand(tmpl string, v interface{}) (string, error) {
+	values, err := c.Values(v)
+	if err != nil {
+		return "", err
+	}
+
+	var known map[string]bool
+	t := structType(v)
+	if t != nil {
+		known = make(map[string]bool)
+		c.rootKeys(t, func(key string, _ tagOptions, _ reflect.Type) {
+			known[key] = true
+		})
+	}
+
+	var b strings.Builder
+	escape := url.PathEscape
+	for {
+		i := strings.IndexAny(tmpl, "{?")
+		if i < 0 {
+			b.WriteString(tmpl)
+			break
+		}
+		b.WriteString(tmpl[:i])
+		if tmpl[i] == '?' {
+			escape = url.QueryEscape
+			b.WriteByte('?')
+			tmpl = tmpl[i+1:]
+			continue
+		}
+
+		j := strings.IndexByte(tmpl[i:], '}')
+		if j < 0 {
+			return "", fmt.Errorf("query: unterminated placeholder in template %q", tmpl[i:])
+		}
+		name := tmpl[i+1 : i+j]
+		vs, ok := values[name]
+		if !ok && known != nil && !knownParam(known, name) {
+			return "", fmt.Errorf("query: template placeholder {%s} does not name a parameter of %v", name, t)
+		}
+		for k, s := range vs {
+			if k > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(escape(s))
+		}
+		tmpl = tmpl[i+j+1:]
+	}
+	return b.String(), nil
+}
+
+// knownParam reports whether name is one of the parameter names in known, as
+// reported by typeKeys, or matches the pattern of a map field in known.
+func knownParam(known map[string]bool, name string) bool {
+	if known[name] {
+		return true
+	}
+	for key := range known {
+		if prefix, suffix, ok := strings.Cut(key, mapKey); ok && matchKey(prefix, suffix, name) {
+			return true
+		}
+	}
+	return false
+}
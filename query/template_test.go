@@ -0,0 +1,71 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	type user struct {
+		Name string `url:"name"`
+	}
+	s := struct {
+		ID    string   `url:"id"`
+		Query string   `url:"q"`
+		Page  int      `url:"page,omitempty"`
+		Tags  []string `url:"tag"`
+		User  user     `url:"user"`
+	}{"a/b c", "go & more", 0, []string{"x,y", "z"}, user{"é"}}
+
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"/users/{id}", "/users/a%2Fb%20c"},
+		{"/search?q={q}", "/search?q=go+%26+more"},
+		{"/search?q={q}&page={page}", "/search?q=go+%26+more&page="},
+		{"/t/{tag}?tag={tag}", "/t/x%2Cy,z?tag=x%2Cy,z"},
+		{"?u={user[name]}", "?u=%C3%A9"},
+		{"/static", "/static"},
+	}
+	for _, tt := range tests {
+		got, err := Expand(tt.tmpl, s)
+		if err != nil {
+			t.Errorf("Expand(%q) returned error: %v", tt.tmpl, err)
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q) returned %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+
+	// maps have no fixed set of parameters, so missing names are empty
+	got, err := Expand("/a?b={b}&c={c}", map[string]string{"b": "1"})
+	if err != nil {
+		t.Errorf("Expand returned error: %v", err)
+	}
+	if want := "/a?b=1&c="; got != want {
+		t.Errorf("Expand returned %q, want %q", got, want)
+	}
+}
+
+func TestExpand_errors(t *testing.T) {
+	s := struct {
+		A string
+	}{"a"}
+
+	for _, tmpl := range []string{
+		"/x/{A",
+		"/x/{B}",
+	} {
+		if got, err := Expand(tmpl, s); err == nil {
+			t.Errorf("Expand(%q) returned %q, want error", tmpl, got)
+		}
+	}
+
+	if _, err := Expand("/{A}", 1); err == nil {
+		t.Errorf("Expand with int input returned nil error, want error")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// A TriBool is a boolean filter that may also be left unset, to express
+// "true", "false" or "don't care", which a plain bool cannot.
+//
+// TriBool values are encoded like bool values, including the "int" option,
+// except that BoolAny is omitted.
+type TriBool int8
+
+// Values of a TriBool.
+const (
+	BoolAny   TriBool = iota // no preference; the zero value
+	BoolTrue                 // filter for true
+	BoolFalse                // filter for false
+)
+
+// TriBoolOf returns BoolTrue or BoolFalse according to b.
+func TriBoolOf(b bool) TriBool {
+	if b {
+		return BoolTrue
+	}
+	return BoolFalse
+}
+
+// Bool returns the boolean value of t, and whether t is set to one.
+func (t TriBool) Bool() (value, ok bool) {
+	return t == BoolTrue, t == BoolTrue || t == BoolFalse
+}
+
+// String returns "true", "false" or "any".
+func (t TriBool) String() string {
+	if b, ok := t.Bool(); ok {
+		return strconv.FormatBool(b)
+	}
+	return "any"
+}
+
+func (t TriBool) encodeValues(c *Config, values url.Values, name string, opts tagOptions) error {
+	if b, ok := t.Bool(); ok {
+		values.Add(name, c.valueString(reflect.ValueOf(b), opts))
+	}
+	return nil
+}
+
+func (t TriBool) keys(name string, opts tagOptions) []string {
+	return []string{name}
+}
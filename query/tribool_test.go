@@ -0,0 +1,44 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestTriBool_encode(t *testing.T) {
+	s := struct {
+		A TriBool
+		B TriBool
+		C TriBool `url:",int"`
+		D TriBool `url:",int"`
+	}{
+		A: BoolTrue,
+		B: BoolAny,
+		C: TriBoolOf(false),
+		D: TriBoolOf(true),
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{
+		"A": {"true"},
+		"C": {"0"},
+		"D": {"1"},
+	}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	for tb, want := range map[TriBool]string{BoolAny: "any", BoolTrue: "true", BoolFalse: "false"} {
+		if got := tb.String(); got != want {
+			t.Errorf("TriBool(%d).String() returned %q, want %q", tb, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// A Field describes a URL parameter of a struct type, as passed to the
+// function called by Visit.
+type Field struct {
+	Param   string       // URL parameter name, such as "user[name]"
+	Type    reflect.Type // type of the struct field producing it
+	Options []string     // options of the struct field, such as "omitempty"
+}
+
+// Visit calls fn for each URL parameter of the struct type of prototype, as
+// listed by Keys, that is present in values, passing its raw values.  It
+// does not modify prototype, and so lets callers build their own handling of
+// the parameters, such as auditing or transformation, on the tag metadata.
+// Parameters of values that prototype cannot produce are ignored.
+//
+// Visit stops at, and returns, the first error returned by fn.
+func Visit(values url.Values, prototype interface{}, fn func(f Field, raw []string) error) error {
+	t := structType(prototype)
+	if t == nil {
+		return nil
+	}
+
+	var fields []Field
+	seen := make(map[string]bool)
+	new(Config).typeKeys(t, "", func(key string, opts tagOptions, ft reflect.Type) {
+		for _, k := range matchingParams(values, key) {
+			if !seen[k] {
+				seen[k] = true
+				fields = append(fields, Field{k, ft, append([]string(nil), opts...)})
+			}
+		}
+	}, map[reflect.Type]bool{})
+
+	for _, f := range fields {
+		if err := fn(f, values[f.Param]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
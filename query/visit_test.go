@@ -0,0 +1,64 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestVisit(t *testing.T) {
+	prototype := struct {
+		Query string `url:"q,omitempty"`
+		IDs   []int  `url:"id,brackets"`
+		User  struct {
+			Name string `url:"name"`
+		} `url:"user,secret"`
+		Page int `url:"page"`
+	}{}
+	values := url.Values{
+		"q":          {"go"},
+		"id[]":       {"1", "2"},
+		"user[name]": {"acme"},
+		"utm_source": {"mail"},
+	}
+
+	type visit struct {
+		f   Field
+		raw []string
+	}
+	var got []visit
+	err := Visit(values, &prototype, func(f Field, raw []string) error {
+		got = append(got, visit{f, raw})
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Visit returned error: %v", err)
+	}
+	want := []visit{
+		{Field{"q", reflect.TypeOf(""), []string{"omitempty"}}, []string{"go"}},
+		{Field{"id[]", reflect.TypeOf([]int{}), []string{"brackets"}}, []string{"1", "2"}},
+		{Field{"user[name]", reflect.TypeOf(""), []string{"secret"}}, []string{"acme"}},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("Visit visited %v, want %v", got, want)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = Visit(values, prototype, func(Field, []string) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("Visit returned %v after %d calls, want %v after 1", err, n, stop)
+	}
+
+	if err := Visit(values, 1, nil); err != nil {
+		t.Errorf("Visit with int prototype returned %v, want nil", err)
+	}
+}
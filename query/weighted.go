@@ -0,0 +1,93 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Weighted is a value with a quality, or preference weight, between 0 and
+// 1, as used by the Accept-Language header and preference-style parameters
+// such as "lang=en;q=0.9,fr;q=0.5".
+//
+// A Weighted value is formatted, and so encoded, as its value followed by
+// ";q=" and its weight, which is left out if it is 1, both as a single field
+// and as an element of a slice.  A slice of them may be encoded as a single
+// parameter using the "comma" option.
+type Weighted[T any] struct {
+	Value T
+	Q     float64
+}
+
+// String returns w in the form "value;q=0.5".
+func (w Weighted[T]) String() string {
+	s := fmt.Sprint(w.Value)
+	if w.Q != 1 {
+		s += ";q=" + strconv.FormatFloat(w.Q, 'f', -1, 64)
+	}
+	return s
+}
+
+func (w Weighted[T]) encodeValues(_ *Config, values url.Values, name string, _ tagOptions) error {
+	values.Add(name, w.String())
+	return nil
+}
+
+func (w Weighted[T]) keys(name string, _ tagOptions) []string {
+	return []string{name}
+}
+
+// ParseWeighted parses a comma-separated list of weighted values, such as
+// "en;q=0.9,fr;q=0.5,de", and returns them ordered from highest to lowest
+// weight, keeping the original order among equal weights.  Values without a
+// "q" parameter have weight 1; any other parameters are kept as part of the
+// value.  Each value is converted using parse; if parse is nil, T must be
+// string.
+func ParseWeighted[T any](s string, parse func(string) (T, error)) ([]Weighted[T], error) {
+	var list []Weighted[T]
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		w := Weighted[T]{Q: 1}
+		var parts []string
+		for i, p := range strings.Split(item, ";") {
+			p = strings.TrimSpace(p)
+			if i > 0 && len(p) > 2 && strings.EqualFold(p[:2], "q=") {
+				q, err := strconv.ParseFloat(p[2:], 64)
+				if err != nil || !(q >= 0 && q <= 1) || strings.ContainsAny(p, "eE") {
+					return nil, fmt.Errorf("query: invalid weight in %q", item)
+				}
+				w.Q = q
+				continue
+			}
+			parts = append(parts, p)
+		}
+
+		raw := strings.Join(parts, ";")
+		if parse == nil {
+			v, ok := interface{}(raw).(T)
+			if !ok {
+				return nil, fmt.Errorf("query: ParseWeighted() needs a parse function for %T", w.Value)
+			}
+			w.Value = v
+		} else {
+			v, err := parse(raw)
+			if err != nil {
+				return nil, err
+			}
+			w.Value = v
+		}
+		list = append(list, w)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Q > list[j].Q })
+	return list, nil
+}
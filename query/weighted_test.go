@@ -0,0 +1,87 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseWeighted(t *testing.T) {
+	got, err := ParseWeighted[string]("fr;q=0.5, en;q=0.9,de,text/html;level=1;Q=0.5,,", nil)
+	if err != nil {
+		t.Fatalf("ParseWeighted returned error: %v", err)
+	}
+	want := []Weighted[string]{
+		{"de", 1},
+		{"en", 0.9},
+		{"fr", 0.5},
+		{"text/html;level=1", 0.5},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("ParseWeighted returned %v, want %v", got, want)
+	}
+
+	ints, err := ParseWeighted("1;q=0.1,2", strconv.Atoi)
+	if err != nil {
+		t.Fatalf("ParseWeighted returned error: %v", err)
+	}
+	if want := []Weighted[int]{{2, 1}, {1, 0.1}}; !reflect.DeepEqual(want, ints) {
+		t.Errorf("ParseWeighted returned %v, want %v", ints, want)
+	}
+
+	for _, s := range []string{"en;q=2", "en;q=x", "en;q=1e-1", "en;q=nan,fr;q=0.5,de", "en;q=NaN"} {
+		if _, err := ParseWeighted[string](s, nil); err == nil {
+			t.Errorf("ParseWeighted(%q) returned nil error", s)
+		}
+	}
+	if _, err := ParseWeighted[int]("1", nil); err == nil {
+		t.Errorf("ParseWeighted[int] without parse function returned nil error")
+	}
+	if _, err := ParseWeighted("x", strconv.Atoi); err == nil {
+		t.Errorf("ParseWeighted with failing parse function returned nil error")
+	}
+}
+
+func TestWeighted_encode(t *testing.T) {
+	s := struct {
+		Lang []Weighted[string] `url:"lang,comma"`
+	}{[]Weighted[string]{{"en", 1}, {"fr", 0.5}}}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{"lang": {"en,fr;q=0.5"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+}
+
+func TestWeighted_encodeField(t *testing.T) {
+	s := struct {
+		Lang Weighted[string]  `url:"lang"`
+		Alt  *Weighted[string] `url:"alt"`
+		None Weighted[string]  `url:"none,omitempty"`
+	}{
+		Lang: Weighted[string]{"en", 0.9},
+		Alt:  &Weighted[string]{"fr", 1},
+	}
+
+	v, err := Values(s)
+	if err != nil {
+		t.Errorf("Values(%v) returned error: %v", s, err)
+	}
+	want := url.Values{"lang": {"en;q=0.9"}, "alt": {"fr"}}
+	if !reflect.DeepEqual(want, v) {
+		t.Errorf("Values(%v) returned %v, want %v", s, v, want)
+	}
+
+	if got, want := Keys(s), []string{"lang", "alt", "none"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys(%v) returned %q, want %q", s, got, want)
+	}
+}